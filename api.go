@@ -1,19 +1,23 @@
-package main
+// Package n26 is a client for the N26 banking API
+package n26
 
 import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"mime"
 	"net/http"
 	"net/url"
-	"strings"
-
-	"golang.org/x/oauth2"
+	"strconv"
+	"sync"
+	"time"
 )
 
+// N26APIUrl is the default base URL of the N26 API
 const N26APIUrl = "https://api.tech26.de"
 
+// N26Error is the error body returned by the N26 API
 type N26Error struct {
 	Error            string `json:"error"`
 	ErrorDescription string `json:"error_description"`
@@ -31,7 +35,11 @@ type N26Account struct {
 	ID               string  `json:"id"`
 }
 
-type N26Transactions []struct {
+// N26Transactions is a list of transactions, newest first
+type N26Transactions []N26Transaction
+
+// N26Transaction is a single booking on the account
+type N26Transaction struct {
 	ID                 string  `json:"id"`
 	UserID             string  `json:"userId"`
 	Type               string  `json:"type"`
@@ -228,24 +236,90 @@ type N26Spaces struct {
 	} `json:"userFeatures"`
 }
 
-// N26Interface includes all possible API Calls
-type N26Interface interface {
-	Transactions(amount string) *N26Transactions
-	Balance() *N26Account
-	Contacts() *N26Contacts
-	Statements() *N26BankStatements
+// N26Stats is a series of balances or the spending per category, From and
+// To are seconds since epoch as in the request
+type N26Stats struct {
+	From  int64          `json:"from"`
+	To    int64          `json:"to"`
+	Type  string         `json:"type"`
+	Items []N26StatsItem `json:"items"`
 }
 
-// N26Credentials contains Email and Password to get a Token
-type N26Credentials struct {
-	Email    string `yaml:"username"`
-	Password string `yaml:"password"`
+// N26StatsItem is a time slice for StatsAccount or a category for StatsCategory
+type N26StatsItem struct {
+	ID     string  `json:"id"`
+	From   int64   `json:"from"`
+	To     int64   `json:"to"`
+	Amount float64 `json:"amount"`
+}
+
+// N26Interface includes all possible API Calls
+type N26Interface interface {
+	Categories(ctx context.Context) (*N26Categories, error)
+	Contacts(ctx context.Context) (*N26Contacts, error)
+	Transactions(ctx context.Context, opts TransactionsOptions) (*N26Transactions, error)
+	Balance(ctx context.Context) (*N26Account, error)
+	AccountLimit(ctx context.Context) (*N26AccountLimit, error)
+	AccountInfo(ctx context.Context) (*N26AccountInfo, error)
+	Status(ctx context.Context) (*N26AccountStatus, error)
+	Stats(ctx context.Context, opts StatsOptions) (*N26Stats, error)
+	Statements(ctx context.Context) (*N26BankStatements, error)
+	Statement(ctx context.Context, statementID string, format StatementFormat, w io.Writer) error
+	Cards(ctx context.Context) (*N26Cards, error)
+	BlockCard(ctx context.Context, cardID string) (*N26CardV1, error)
+	UnblockCard(ctx context.Context, cardID string) (*N26CardV1, error)
+	Savings(ctx context.Context) (*N26Savings, error)
+	Spaces(ctx context.Context) (*N26Spaces, error)
+}
+
+var _ N26Interface = (*Client)(nil)
+
+// Client talks to the N26 API on behalf of a customer
+type Client struct {
+	Email    string
+	Password string
+	// PasswordFunc returns the password when Password is empty, it is only
+	// called when a login is needed
+	PasswordFunc func() (string, error)
+	// DeviceToken identifies this installation towards N26
+	DeviceToken string
+	// MFAType selects the second factor, MFATypeApp by default
+	MFAType string
+	// MFAPrompt is called once the two-factor challenge was sent, for
+	// MFATypeSMS it returns the one-time password
+	MFAPrompt func(challengeType string) (string, error)
+	// TokenStore keeps the OAuth token between runs, optional
+	TokenStore TokenStore
+
+	baseURL     string
+	httpClient  *http.Client
+	transport   http.RoundTripper
+	timeout     time.Duration
+	userAgent   string
+	retryPolicy *RetryPolicy
+
+	once   sync.Once
+	client *http.Client
+}
+
+// NewClient returns a client logging in with email and password
+func NewClient(email, password string, opts ...Option) *Client {
+	c := &Client{Email: email, Password: password}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Categories returns all available categories
-func (n26 *N26Credentials) Categories() (*N26Categories, error) {
+func (c *Client) Categories(ctx context.Context) (*N26Categories, error) {
 	categories := &N26Categories{}
-	resp, err := n26.callAPI("GET", "/api/smrt/categories", nil)
+	resp, err := c.callAPI(ctx, "GET", "/api/smrt/categories", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	err = checkHTTPStatus(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -257,9 +331,14 @@ func (n26 *N26Credentials) Categories() (*N26Categories, error) {
 }
 
 // Contacts returns all customer contacts
-func (n26 *N26Credentials) Contacts() (*N26Contacts, error) {
+func (c *Client) Contacts(ctx context.Context) (*N26Contacts, error) {
 	contacts := &N26Contacts{}
-	resp, err := n26.callAPI("GET", "/api/smrt/contacts", nil)
+	resp, err := c.callAPI(ctx, "GET", "/api/smrt/contacts", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	err = checkHTTPStatus(resp)
 	if err != nil {
 		return nil, err
 	}
@@ -270,15 +349,46 @@ func (n26 *N26Credentials) Contacts() (*N26Contacts, error) {
 	return contacts, nil
 }
 
+// TransactionsOptions filters transactions, zero values are left out
+type TransactionsOptions struct {
+	// Limit is the maximum number of transactions
+	Limit int
+	// From and To restrict the time the transactions became visible
+	From time.Time
+	To   time.Time
+	// LastID returns only transactions older than the one with this ID,
+	// used to page through the history
+	LastID string
+}
+
+func (opts TransactionsOptions) values() *url.Values {
+	v := &url.Values{}
+	if opts.Limit > 0 {
+		v.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if !opts.From.IsZero() || !opts.To.IsZero() {
+		to := opts.To
+		if to.IsZero() {
+			to = time.Now()
+		}
+		v.Set("from", strconv.FormatInt(toMillis(opts.From), 10))
+		v.Set("to", strconv.FormatInt(toMillis(to), 10))
+	}
+	if opts.LastID != "" {
+		v.Set("lastId", opts.LastID)
+	}
+	return v
+}
+
 // Transactions returns the latest transactions from customers bank account
-func (n26 *N26Credentials) Transactions(amount string) (*N26Transactions, error) {
+func (c *Client) Transactions(ctx context.Context, opts TransactionsOptions) (*N26Transactions, error) {
 	transactions := &N26Transactions{}
-	v := &url.Values{}
-	v.Add("limit", amount)
-	resp, err := n26.callAPI("GET", "/api/smrt/transactions", v)
+	v := opts.values()
+	resp, err := c.callAPI(ctx, "GET", "/api/smrt/transactions", v)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	err = checkHTTPStatus(resp)
 	if err != nil {
 		return nil, err
@@ -291,12 +401,13 @@ func (n26 *N26Credentials) Transactions(amount string) (*N26Transactions, error)
 }
 
 // Balance returns customers current balance
-func (n26 *N26Credentials) Balance() (*N26Account, error) {
+func (c *Client) Balance(ctx context.Context) (*N26Account, error) {
 	account := &N26Account{}
-	resp, err := n26.callAPI("GET", "/api/accounts", nil)
+	resp, err := c.callAPI(ctx, "GET", "/api/accounts", nil)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	err = checkHTTPStatus(resp)
 	if err != nil {
 		return nil, err
@@ -308,12 +419,14 @@ func (n26 *N26Credentials) Balance() (*N26Account, error) {
 	return account, nil
 }
 
-func (n26 *N26Credentials) AccountLimit() (*N26AccountLimit, error) {
+// AccountLimit returns the withdrawal and payment limits of the account
+func (c *Client) AccountLimit(ctx context.Context) (*N26AccountLimit, error) {
 	accountLimit := &N26AccountLimit{}
-	resp, err := n26.callAPI("GET", "/api/settings/account/limits", nil)
+	resp, err := c.callAPI(ctx, "GET", "/api/settings/account/limits", nil)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	err = checkHTTPStatus(resp)
 	if err != nil {
 		return nil, err
@@ -325,12 +438,14 @@ func (n26 *N26Credentials) AccountLimit() (*N26AccountLimit, error) {
 	return accountLimit, nil
 }
 
-func (n26 *N26Credentials) AccountInfo() (*N26AccountInfo, error) {
+// AccountInfo returns the personal information of the customer
+func (c *Client) AccountInfo(ctx context.Context) (*N26AccountInfo, error) {
 	accountInfo := &N26AccountInfo{}
-	resp, err := n26.callAPI("GET", "/api/me", nil)
+	resp, err := c.callAPI(ctx, "GET", "/api/me", nil)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	err = checkHTTPStatus(resp)
 	if err != nil {
 		return nil, err
@@ -342,12 +457,14 @@ func (n26 *N26Credentials) AccountInfo() (*N26AccountInfo, error) {
 	return accountInfo, nil
 }
 
-func (n26 *N26Credentials) Statements() (*N26BankStatements, error) {
+// Statements returns all available bank statements
+func (c *Client) Statements(ctx context.Context) (*N26BankStatements, error) {
 	bankStatements := &N26BankStatements{}
-	resp, err := n26.callAPI("GET", "/api/statements", nil)
+	resp, err := c.callAPI(ctx, "GET", "/api/statements", nil)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	err = checkHTTPStatus(resp)
 	if err != nil {
 		return nil, err
@@ -359,53 +476,117 @@ func (n26 *N26Credentials) Statements() (*N26BankStatements, error) {
 	return bankStatements, nil
 }
 
-func (n26 *N26Credentials) Statement(statementID string) {
-	resp, err := n26.callAPI("GET", "/api/statements/"+statementID, nil)
+// StatementFormat is the file format of a bank statement
+type StatementFormat string
+
+const (
+	// StatementPDF is the printable statement
+	StatementPDF StatementFormat = "pdf"
+	// StatementCSV lists the transactions of the statement
+	StatementCSV StatementFormat = "csv"
+)
+
+func (f StatementFormat) contentType() string {
+	if f == StatementCSV {
+		return "text/csv"
+	}
+	return "application/pdf"
+}
+
+// Statement streams a bank statement as PDF or CSV to w, nothing is written
+// when the API returns an error or an HTML or JSON page instead of a file
+func (c *Client) Statement(ctx context.Context, statementID string, format StatementFormat, w io.Writer) error {
+	header := http.Header{}
+	header.Set("Accept", format.contentType())
+	resp, err := c.callAPIWithHeader(ctx, "GET", "/api/statements/"+statementID, nil, header)
 	if err != nil {
-		fmt.Println(err)
+		return err
 	}
+	defer resp.Body.Close()
 	err = checkHTTPStatus(resp)
 	if err != nil {
-		fmt.Println(err)
+		return err
 	}
-	byt, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Println(err)
+	// the content type of files varies, only reject error pages
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err == nil && (mediaType == "text/html" || mediaType == "application/json") {
+			return fmt.Errorf("statement %s: requested %s but got %s", statementID, format.contentType(), mediaType)
+		}
 	}
-	ioutil.WriteFile(
-		fmt.Sprintf("%s.pdf", statementID),
-		byt,
-		0750,
-	)
+	_, err = io.Copy(w, resp.Body)
+	return err
 }
 
-func (n26 *N26Credentials) Stats() {
+const (
+	// StatsAccount is the balance at the end of every slice
+	StatsAccount = "acct"
+	// StatsCategory is the amount spent per category
+	StatsCategory = "cat"
+
+	defaultStatsSlices = 25
+)
+
+// StatsOptions selects the statistics, by default the balance of the last
+// 30 days in 25 slices
+type StatsOptions struct {
+	// Type is StatsAccount or StatsCategory
+	Type string
+	From time.Time
+	To   time.Time
+	// Slices is the number of time slices between From and To
+	Slices int
+}
+
+func (opts StatsOptions) values(now time.Time) *url.Values {
+	if opts.Type == "" {
+		opts.Type = StatsAccount
+	}
+	if opts.To.IsZero() {
+		opts.To = now
+	}
+	if opts.From.IsZero() {
+		opts.From = opts.To.AddDate(0, 0, -30)
+	}
+	if opts.Slices <= 0 {
+		opts.Slices = defaultStatsSlices
+	}
 	v := &url.Values{}
-	v.Set("type", "acct")
-	v.Add("from", "1451602800")
-	v.Add("to", "1451732400")
-	v.Add("numSlices", "25")
-	resp, err := n26.callAPI("GET", "/api/accounts/stats/", v)
+	v.Set("type", opts.Type)
+	// unlike transactions, stats take seconds since epoch
+	v.Set("from", strconv.FormatInt(opts.From.Unix(), 10))
+	v.Set("to", strconv.FormatInt(opts.To.Unix(), 10))
+	v.Set("numSlices", strconv.Itoa(opts.Slices))
+	return v
+}
+
+// Stats returns the account statistics
+func (c *Client) Stats(ctx context.Context, opts StatsOptions) (*N26Stats, error) {
+	stats := &N26Stats{}
+	resp, err := c.callAPI(ctx, "GET", "/api/accounts/stats/", opts.values(time.Now()))
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
+	defer resp.Body.Close()
 	err = checkHTTPStatus(resp)
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
-	byt, err := ioutil.ReadAll(resp.Body)
+	err = json.NewDecoder(resp.Body).Decode(stats)
 	if err != nil {
-		fmt.Println(err)
+		return nil, err
 	}
-	fmt.Println(string(byt))
+	return stats, nil
 }
 
-func (n26 *N26Credentials) Cards() (*N26Cards, error) {
+// Cards returns all cards of the customer
+func (c *Client) Cards(ctx context.Context) (*N26Cards, error) {
 	cards := &N26Cards{}
-	resp, err := n26.callAPI("GET", "/api/v2/cards", nil)
+	resp, err := c.callAPI(ctx, "GET", "/api/v2/cards", nil)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	err = checkHTTPStatus(resp)
 	if err != nil {
 		return nil, err
@@ -417,15 +598,17 @@ func (n26 *N26Credentials) Cards() (*N26Cards, error) {
 	return cards, nil
 }
 
-func (n26 *N26Credentials) BlockCard(cardID string) (*N26CardV1, error) {
+// BlockCard disables a card
+func (c *Client) BlockCard(ctx context.Context, cardID string) (*N26CardV1, error) {
 	card := &N26CardV1{}
 	card.Status = "DISABLED"
-	resp, err := n26.callAPI("POST",
+	resp, err := c.callAPI(ctx, "POST",
 		fmt.Sprintf("/api/cards/%s/block", cardID),
 		nil)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	err = checkHTTPStatus(resp)
 	if err != nil {
 		return nil, err
@@ -437,15 +620,17 @@ func (n26 *N26Credentials) BlockCard(cardID string) (*N26CardV1, error) {
 	return card, nil
 }
 
-func (n26 *N26Credentials) UnblockCard(cardID string) (*N26CardV1, error) {
+// UnblockCard enables a blocked card
+func (c *Client) UnblockCard(ctx context.Context, cardID string) (*N26CardV1, error) {
 	card := &N26CardV1{}
 	card.Status = "ACTIVE"
-	resp, err := n26.callAPI("POST",
+	resp, err := c.callAPI(ctx, "POST",
 		fmt.Sprintf("/api/cards/%s/unblock", cardID),
 		nil)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	err = checkHTTPStatus(resp)
 	if err != nil {
 		return nil, err
@@ -456,12 +641,15 @@ func (n26 *N26Credentials) UnblockCard(cardID string) (*N26CardV1, error) {
 	}
 	return card, nil
 }
-func (n26 *N26Credentials) Status() (*N26AccountStatus, error) {
+
+// Status returns the progress of the account setup
+func (c *Client) Status(ctx context.Context) (*N26AccountStatus, error) {
 	accountStatus := &N26AccountStatus{}
-	resp, err := n26.callAPI("GET", "/api/me/statuses", nil)
+	resp, err := c.callAPI(ctx, "GET", "/api/me/statuses", nil)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	err = checkHTTPStatus(resp)
 	if err != nil {
 		return nil, err
@@ -473,12 +661,14 @@ func (n26 *N26Credentials) Status() (*N26AccountStatus, error) {
 	return accountStatus, nil
 }
 
-func (n26 *N26Credentials) Savings() (*N26Savings, error) {
+// Savings returns savings and investment accounts
+func (c *Client) Savings(ctx context.Context) (*N26Savings, error) {
 	savings := &N26Savings{}
-	resp, err := n26.callAPI("GET", "/api/hub/savings/accounts", nil)
+	resp, err := c.callAPI(ctx, "GET", "/api/hub/savings/accounts", nil)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	err = checkHTTPStatus(resp)
 	if err != nil {
 		return nil, err
@@ -490,12 +680,14 @@ func (n26 *N26Credentials) Savings() (*N26Savings, error) {
 	return savings, nil
 }
 
-func (n26 *N26Credentials) Spaces() (*N26Spaces, error) {
+// Spaces returns all spaces with their balance
+func (c *Client) Spaces(ctx context.Context) (*N26Spaces, error) {
 	spaces := &N26Spaces{}
-	resp, err := n26.callAPI("GET", "/api/spaces", nil)
+	resp, err := c.callAPI(ctx, "GET", "/api/spaces", nil)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	err = checkHTTPStatus(resp)
 	if err != nil {
 		return nil, err
@@ -507,51 +699,49 @@ func (n26 *N26Credentials) Spaces() (*N26Spaces, error) {
 	return spaces, nil
 }
 
-func (n26 *N26Credentials) callAPI(method, path string, v *url.Values) (*http.Response, error) {
-	client, err := n26.newClient()
+func (c *Client) callAPI(ctx context.Context, method, path string, v *url.Values) (*http.Response, error) {
+	return c.callAPIWithHeader(ctx, method, path, v, nil)
+}
+
+func (c *Client) callAPIWithHeader(ctx context.Context, method, path string, v *url.Values, header http.Header) (*http.Response, error) {
+	c.once.Do(func() {
+		c.client = c.newClient()
+	})
+	req, err := http.NewRequest(method, c.apiURL(path), nil)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest(method, N26APIUrl, nil)
-	if err != nil {
-		return nil, err
+	for key, values := range header {
+		req.Header[key] = values
 	}
-	req.URL.Path = path
+	c.setUserAgent(req)
 	if v != nil {
 		req.URL.RawQuery = v.Encode()
 	}
-	resp, err := client.Do(req)
+	resp, err := c.client.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func (n26 *N26Credentials) newClient() (*http.Client, error) {
-	ctx := context.Background()
-	conf := oauth2.Config{
-		ClientID:     "android",
-		ClientSecret: "secret",
-		Endpoint: oauth2.Endpoint{
-			TokenURL: N26APIUrl + "/oauth/token",
-		},
+func (c *Client) newClient() *http.Client {
+	httpClient := c.baseHTTPClient()
+	policy := DefaultRetryPolicy
+	if c.retryPolicy != nil {
+		policy = *c.retryPolicy
 	}
-	tk, err := conf.PasswordCredentialsToken(ctx, n26.Email, n26.Password)
-	if err != nil {
-		return nil, err
+	httpClient.Transport = &authTransport{
+		source: &tokenSource{client: c},
+		base:   &retryTransport{policy: policy, base: httpClient.Transport},
 	}
-	return conf.Client(ctx, tk), nil
+	return httpClient
 }
 
-func checkHTTPStatus(resp *http.Response) error {
-	if resp.StatusCode >= http.StatusBadRequest {
-		n26Error := &N26Error{}
-		err := json.NewDecoder(resp.Body).Decode(n26Error)
-		if err != nil {
-			return err
-		}
-		err = fmt.Errorf("%s", strings.Replace(n26Error.ErrorDescription, ":", "", -1))
-		return err
+// toMillis converts a time to the milliseconds since epoch used by the API
+func toMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
 	}
-	return nil
+	return t.UnixNano() / int64(time.Millisecond)
 }
@@ -0,0 +1,508 @@
+package n26_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/njuettner/n26"
+	"github.com/njuettner/n26/n26test"
+	"golang.org/x/oauth2"
+)
+
+// memoryTokenStore keeps the token in memory
+type memoryTokenStore struct {
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+func (s *memoryTokenStore) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == nil {
+		return nil, nil
+	}
+	token := *s.token
+	return &token, nil
+}
+
+func (s *memoryTokenStore) SetToken(token *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *token
+	s.token = &stored
+	return nil
+}
+
+// recordingTransport records the requests and the grant types of the token
+// requests, failures makes the first requests of an API path fail with the
+// status and Retry-After header of the response
+type recordingTransport struct {
+	failures   map[string]int
+	status     int
+	retryAfter string
+
+	mu       sync.Mutex
+	requests []*http.Request
+	grants   []string
+	attempts map[string]int
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.requests = append(t.requests, req)
+	if req.URL.Path == "/oauth/token" {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.mu.Unlock()
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		grant := ""
+		for _, pair := range strings.Split(string(body), "&") {
+			if strings.HasPrefix(pair, "grant_type=") {
+				grant = strings.TrimPrefix(pair, "grant_type=")
+			}
+		}
+		t.grants = append(t.grants, grant)
+	}
+	if t.attempts == nil {
+		t.attempts = map[string]int{}
+	}
+	t.attempts[req.Method+" "+req.URL.Path]++
+	fail := t.attempts[req.Method+" "+req.URL.Path] <= t.failures[req.URL.Path]
+	t.mu.Unlock()
+	if fail {
+		resp := &http.Response{
+			StatusCode: t.status,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(`{"error":"unavailable","error_description":"Try again later"}`)),
+			Request:    req,
+		}
+		if t.retryAfter != "" {
+			resp.Header.Set("Retry-After", t.retryAfter)
+		}
+		return resp, nil
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (t *recordingTransport) attemptsOf(method, path string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.attempts[method+" "+path]
+}
+
+func (t *recordingTransport) grantTypes() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.grants...)
+}
+
+func TestLoginMFAApp(t *testing.T) {
+	defer n26.SetMFAPollInterval(time.Millisecond)()
+	server := n26test.NewServer()
+	defer server.Close()
+	server.API.MFA = true
+	server.API.MFAPendingPolls = 2
+
+	transport := &recordingTransport{}
+	store := &memoryTokenStore{}
+	client := server.Client(n26.WithTransport(transport))
+	client.TokenStore = store
+	var challenges []string
+	client.MFAPrompt = func(challengeType string) (string, error) {
+		challenges = append(challenges, challengeType)
+		return "", nil
+	}
+
+	err := client.Login(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(challenges) != "[oob]" {
+		t.Errorf("MFAPrompt called with %v, want [oob]", challenges)
+	}
+	want := "[password mfa_oob mfa_oob mfa_oob]"
+	if grants := fmt.Sprint(transport.grantTypes()); grants != want {
+		t.Errorf("grant types = %s, want %s", grants, want)
+	}
+	token, _ := store.Token()
+	if !token.Valid() || token.RefreshToken == "" {
+		t.Fatalf("stored token = %+v, want a valid token with refresh token", token)
+	}
+	_, err = client.Balance(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoginMFASMS(t *testing.T) {
+	server := n26test.NewServer()
+	defer server.Close()
+	server.API.MFA = true
+
+	client := server.Client()
+	client.MFAType = n26.MFATypeSMS
+	client.MFAPrompt = func(challengeType string) (string, error) {
+		return n26test.OTP + "\n", nil
+	}
+	err := client.Login(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client = server.Client()
+	client.MFAType = n26.MFATypeSMS
+	client.MFAPrompt = func(challengeType string) (string, error) {
+		return "000000", nil
+	}
+	err = client.Login(context.Background())
+	var apiErr *n26.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "invalid_otp" {
+		t.Errorf("login with wrong OTP = %v, want invalid_otp", err)
+	}
+}
+
+func TestLoginWrongPassword(t *testing.T) {
+	server := n26test.NewServer()
+	defer server.Close()
+
+	client := n26.NewClient(n26test.Email, "wrong", n26.WithBaseURL(server.URL))
+	err := client.Login(context.Background())
+	if !n26.IsUnauthorized(err) {
+		t.Errorf("login with wrong password = %v, want unauthorized", err)
+	}
+}
+
+func TestRefreshExpiredToken(t *testing.T) {
+	server := n26test.NewServer()
+	defer server.Close()
+
+	transport := &recordingTransport{}
+	store := &memoryTokenStore{}
+	client := server.Client(n26.WithTransport(transport))
+	client.TokenStore = store
+	err := client.Login(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, _ := store.Token()
+	expired.Expiry = time.Now().Add(-time.Minute)
+	store.SetToken(expired)
+
+	client = server.Client(n26.WithTransport(transport))
+	client.TokenStore = store
+	client.Password = ""
+	client.PasswordFunc = func() (string, error) {
+		return "", errors.New("password must not be needed")
+	}
+	_, err = client.Balance(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[password refresh_token]"
+	if grants := fmt.Sprint(transport.grantTypes()); grants != want {
+		t.Errorf("grant types = %s, want %s", grants, want)
+	}
+	refreshed, _ := store.Token()
+	if refreshed.AccessToken == expired.AccessToken || !refreshed.Valid() {
+		t.Errorf("stored token was not refreshed: %+v", refreshed)
+	}
+}
+
+func TestRefreshServerError(t *testing.T) {
+	server := n26test.NewServer()
+	defer server.Close()
+
+	transport := &recordingTransport{
+		failures: map[string]int{"/oauth/token": 1},
+		status:   http.StatusServiceUnavailable,
+	}
+	store := &memoryTokenStore{}
+	store.SetToken(&oauth2.Token{
+		AccessToken:  "expired",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(-time.Minute),
+	})
+	client := server.Client(n26.WithTransport(transport))
+	client.TokenStore = store
+	_, err := client.Balance(context.Background())
+	var apiErr *n26.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got %v, want 503", err)
+	}
+	want := "[refresh_token]"
+	if grants := fmt.Sprint(transport.grantTypes()); grants != want {
+		t.Errorf("grant types = %s, want %s", grants, want)
+	}
+}
+
+func TestCorruptTokenFile(t *testing.T) {
+	server := n26test.NewServer()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "n26")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "n26-token.json")
+	err = ioutil.WriteFile(path, []byte("{not json"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := server.Client()
+	client.TokenStore = n26.NewFileTokenStore(path)
+	_, err = client.Balance(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := client.TokenStore.Token()
+	if err != nil || !token.Valid() {
+		t.Errorf("token file was not replaced: %v %v", token, err)
+	}
+}
+
+func TestRevokedTokenConcurrentRequests(t *testing.T) {
+	server := n26test.NewServer()
+	defer server.Close()
+
+	transport := &recordingTransport{}
+	store := &memoryTokenStore{}
+	store.SetToken(&oauth2.Token{
+		AccessToken:  "revoked",
+		TokenType:    "bearer",
+		RefreshToken: "revoked",
+		Expiry:       time.Now().Add(time.Hour),
+	})
+	client := server.Client(n26.WithTransport(transport))
+	client.TokenStore = store
+	statementID := server.API.Statements[0].ID
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- client.Statement(context.Background(), statementID, n26.StatementPDF, ioutil.Discard)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	// the first request rejected with 401 logs in again, the others use its token
+	want := "[refresh_token password]"
+	if grants := fmt.Sprint(transport.grantTypes()); grants != want {
+		t.Errorf("grant types = %s, want %s", grants, want)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	server := n26test.NewServer()
+	defer server.Close()
+
+	err := server.Client().Statement(context.Background(), "statement-1970-01", n26.StatementPDF, ioutil.Discard)
+	var apiErr *n26.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want an APIError", err)
+	}
+	want := n26.APIError{
+		StatusCode:  http.StatusNotFound,
+		Code:        "not_found",
+		Description: "Statement not found",
+		Method:      "GET",
+		Endpoint:    "/api/statements/statement-1970-01",
+	}
+	if len(apiErr.Body) == 0 {
+		t.Error("response body is missing")
+	}
+	apiErr.Body = nil
+	if !reflect.DeepEqual(*apiErr, want) {
+		t.Errorf("got %+v, want %+v", *apiErr, want)
+	}
+	if !n26.IsNotFound(err) {
+		t.Errorf("IsNotFound(%v) = false", err)
+	}
+	if err.Error() != "GET /api/statements/statement-1970-01: 404 Statement not found" {
+		t.Errorf("unexpected message %q", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	server := n26test.NewServer()
+	defer server.Close()
+
+	transport := &recordingTransport{
+		failures:   map[string]int{"/api/accounts": 2},
+		status:     http.StatusTooManyRequests,
+		retryAfter: "0",
+	}
+	// the backoff is longer than the test, only Retry-After lets it pass
+	client := server.Client(n26.WithTransport(transport), n26.WithRetryPolicy(n26.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Minute,
+		MaxDelay:    time.Minute,
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := client.Balance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts := transport.attemptsOf("GET", "/api/accounts"); attempts != 3 {
+		t.Errorf("got %d attempts, want 3", attempts)
+	}
+}
+
+func TestRetryAfterTooLong(t *testing.T) {
+	server := n26test.NewServer()
+	defer server.Close()
+
+	transport := &recordingTransport{
+		failures:   map[string]int{"/api/accounts": 1},
+		status:     http.StatusTooManyRequests,
+		retryAfter: "120",
+	}
+	client := server.Client(n26.WithTransport(transport), n26.WithRetryPolicy(n26.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Minute,
+	}))
+	_, err := client.Balance(context.Background())
+	if !n26.IsRateLimited(err) {
+		t.Errorf("got %v, want rate limited", err)
+	}
+	if attempts := transport.attemptsOf("GET", "/api/accounts"); attempts != 1 {
+		t.Errorf("got %d attempts, want 1", attempts)
+	}
+}
+
+func TestNoRetryOnPost(t *testing.T) {
+	server := n26test.NewServer()
+	defer server.Close()
+
+	cardID := server.API.Cards[0].ID
+	path := "/api/cards/" + cardID + "/block"
+	transport := &recordingTransport{
+		failures: map[string]int{path: 1},
+		status:   http.StatusServiceUnavailable,
+	}
+	client := server.Client(n26.WithTransport(transport), n26.WithRetryPolicy(n26.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+	}))
+	_, err := client.BlockCard(context.Background(), cardID)
+	var apiErr *n26.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got %v, want 503", err)
+	}
+	if attempts := transport.attemptsOf("POST", path); attempts != 1 {
+		t.Errorf("got %d attempts, want 1", attempts)
+	}
+}
+
+func TestTransactionsIter(t *testing.T) {
+	server := n26test.NewServer()
+	defer server.Close()
+
+	transport := &recordingTransport{}
+	client := server.Client(n26.WithTransport(transport))
+	it := client.TransactionsIter(context.Background(), n26.TransactionsOptions{Limit: 2})
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Transaction().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var want, wantLastIDs []string
+	for i, transaction := range server.API.Transactions {
+		want = append(want, transaction.ID)
+		if i%2 == 1 {
+			wantLastIDs = append(wantLastIDs, transaction.ID)
+		}
+	}
+	if fmt.Sprint(ids) != fmt.Sprint(want) {
+		t.Errorf("got transactions %v, want %v", ids, want)
+	}
+	var lastIDs []string
+	first := true
+	for _, req := range transport.requests {
+		if req.URL.Path != "/api/smrt/transactions" {
+			continue
+		}
+		if req.URL.Query().Get("limit") != "2" {
+			t.Errorf("request %s without page size", req.URL)
+		}
+		lastID := req.URL.Query().Get("lastId")
+		if first {
+			if lastID != "" {
+				t.Errorf("first page requested with lastId %s", lastID)
+			}
+			first = false
+			continue
+		}
+		lastIDs = append(lastIDs, lastID)
+	}
+	// with an even number of transactions the last page is empty
+	if fmt.Sprint(lastIDs) != fmt.Sprint(wantLastIDs) {
+		t.Errorf("got lastIds %v, want %v", lastIDs, wantLastIDs)
+	}
+}
+
+func TestStatementContentType(t *testing.T) {
+	api := n26test.NewHandler()
+	statementID := api.Statements[0].ID
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/statements/"+statementID {
+			api.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		io.WriteString(w, "%PDF-1.4\n")
+	}))
+	defer server.Close()
+	client := n26.NewClient(n26test.Email, n26test.Password, n26.WithBaseURL(server.URL))
+
+	for _, test := range []struct {
+		contentType string
+		ok          bool
+	}{
+		{"application/pdf", true},
+		{"application/octet-stream", true},
+		{"text/plain; charset=utf-8", true},
+		{"text/html; charset=utf-8", false},
+		{"application/json", false},
+	} {
+		contentType = test.contentType
+		buf := &bytes.Buffer{}
+		err := client.Statement(context.Background(), statementID, n26.StatementPDF, buf)
+		if test.ok && (err != nil || buf.String() != "%PDF-1.4\n") {
+			t.Errorf("%s: got %q, %v", test.contentType, buf, err)
+		}
+		if !test.ok && (err == nil || buf.Len() > 0) {
+			t.Errorf("%s: got %q, %v, want an error", test.contentType, buf, err)
+		}
+	}
+}
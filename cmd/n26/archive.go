@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/njuettner/n26"
+	_ "modernc.org/sqlite"
+)
+
+// errOffline is returned for API calls the archive can't answer
+var errOffline = errors.New("not available in --offline mode, the archive only keeps what n26 sync downloaded")
+
+const archiveSchema = `
+CREATE TABLE IF NOT EXISTS transactions (
+	id         TEXT PRIMARY KEY,
+	visible_ts INTEGER NOT NULL,
+	pending    INTEGER NOT NULL,
+	data       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS transactions_visible_ts ON transactions (visible_ts);
+CREATE TABLE IF NOT EXISTS snapshots (
+	name    TEXT PRIMARY KEY,
+	updated INTEGER NOT NULL,
+	data    TEXT NOT NULL
+);
+`
+
+// archive is a local SQLite copy of the account, it serves the read-only
+// API calls for --offline
+type archive struct {
+	db *sql.DB
+}
+
+var _ n26.N26Interface = (*archive)(nil)
+
+// syncResult is printed by n26 sync
+type syncResult struct {
+	New           int   `json:"new"`
+	Updated       int   `json:"updated"`
+	Removed       int   `json:"removed"`
+	Transactions  int   `json:"transactions"`
+	LastVisibleTS int64 `json:"lastVisibleTS"`
+	// FailedSnapshots maps snapshots that could not be updated to the error,
+	// the archive keeps their previous data
+	FailedSnapshots map[string]string `json:"failedSnapshots,omitempty"`
+}
+
+func openArchive(path string) (*archive, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return nil, err
+	}
+	// the archive holds personal data, like the token it is only readable
+	// by the current user
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	err = os.Chmod(path, 0600)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.Exec(archiveSchema)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not open archive %s: %s", path, err)
+	}
+	return &archive{db: db}, nil
+}
+
+// openArchiveReadOnly opens an archive that n26 sync already created
+func openArchiveReadOnly(path string) (*archive, error) {
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no archive at %s, run n26 sync first", path)
+	}
+	return openArchive(path)
+}
+
+func (a *archive) Close() error {
+	return a.db.Close()
+}
+
+// sync downloads transactions since the last synced one, pending
+// transactions are fetched again until they are confirmed
+func (a *archive) sync(ctx context.Context, client n26.N26Interface) (*syncResult, error) {
+	var from sql.NullInt64
+	err := a.db.QueryRowContext(ctx, `SELECT min(visible_ts) FROM transactions WHERE pending = 1`).Scan(&from)
+	if err != nil {
+		return nil, err
+	}
+	if !from.Valid {
+		err = a.db.QueryRowContext(ctx, `SELECT max(visible_ts) FROM transactions`).Scan(&from)
+		if err != nil {
+			return nil, err
+		}
+	}
+	opts := n26.TransactionsOptions{}
+	if from.Valid {
+		opts.From = millisToTime(from.Int64)
+	}
+	transactions, err := n26.NewTransactionsIterator(ctx, client, opts).All()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	result := &syncResult{}
+	seen := map[string]bool{}
+	for _, transaction := range *transactions {
+		seen[transaction.ID] = true
+		data, err := json.Marshal(transaction)
+		if err != nil {
+			return nil, err
+		}
+		var stored string
+		err = tx.QueryRowContext(ctx, `SELECT data FROM transactions WHERE id = ?`, transaction.ID).Scan(&stored)
+		switch {
+		case err == sql.ErrNoRows:
+			result.New++
+		case err != nil:
+			return nil, err
+		case stored == string(data):
+			continue
+		default:
+			result.Updated++
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO transactions (id, visible_ts, pending, data) VALUES (?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET visible_ts = excluded.visible_ts, pending = excluded.pending, data = excluded.data`,
+			transaction.ID, transaction.VisibleTS, transaction.Pending, string(data))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if from.Valid {
+		// pending transactions that disappeared were declined or expired
+		rows, err := tx.QueryContext(ctx, `SELECT id FROM transactions WHERE pending = 1 AND visible_ts >= ?`, from.Int64)
+		if err != nil {
+			return nil, err
+		}
+		var removed []string
+		for rows.Next() {
+			var id string
+			err = rows.Scan(&id)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if !seen[id] {
+				removed = append(removed, id)
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+		for _, id := range removed {
+			_, err = tx.ExecContext(ctx, `DELETE FROM transactions WHERE id = ?`, id)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result.Removed = len(removed)
+	}
+	err = tx.QueryRowContext(ctx, `SELECT count(*), coalesce(max(visible_ts), 0) FROM transactions`).Scan(&result.Transactions, &result.LastVisibleTS)
+	if err != nil {
+		return nil, err
+	}
+	// transactions are kept even if a snapshot can't be updated
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := map[string]func(context.Context) (interface{}, error){
+		"balance":    func(ctx context.Context) (interface{}, error) { return client.Balance(ctx) },
+		"limits":     func(ctx context.Context) (interface{}, error) { return client.AccountLimit(ctx) },
+		"info":       func(ctx context.Context) (interface{}, error) { return client.AccountInfo(ctx) },
+		"status":     func(ctx context.Context) (interface{}, error) { return client.Status(ctx) },
+		"categories": func(ctx context.Context) (interface{}, error) { return client.Categories(ctx) },
+		"contacts":   func(ctx context.Context) (interface{}, error) { return client.Contacts(ctx) },
+		"statements": func(ctx context.Context) (interface{}, error) { return client.Statements(ctx) },
+		"cards":      func(ctx context.Context) (interface{}, error) { return client.Cards(ctx) },
+		"savings":    func(ctx context.Context) (interface{}, error) { return client.Savings(ctx) },
+		"spaces":     func(ctx context.Context) (interface{}, error) { return client.Spaces(ctx) },
+	}
+	for name, fetch := range snapshots {
+		err := a.saveSnapshot(ctx, name, fetch)
+		if err != nil {
+			if result.FailedSnapshots == nil {
+				result.FailedSnapshots = map[string]string{}
+			}
+			result.FailedSnapshots[name] = err.Error()
+		}
+	}
+	return result, nil
+}
+
+func (a *archive) saveSnapshot(ctx context.Context, name string, fetch func(context.Context) (interface{}, error)) error {
+	v, err := fetch(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = a.db.ExecContext(ctx, `INSERT INTO snapshots (name, updated, data) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET updated = excluded.updated, data = excluded.data`,
+		name, time.Now().Unix(), string(data))
+	return err
+}
+
+// Transactions returns archived transactions, newest first
+func (a *archive) Transactions(ctx context.Context, opts n26.TransactionsOptions) (*n26.N26Transactions, error) {
+	var where []string
+	var args []interface{}
+	if !opts.From.IsZero() {
+		where = append(where, "visible_ts >= ?")
+		args = append(args, opts.From.UnixNano()/int64(time.Millisecond))
+	}
+	if !opts.To.IsZero() {
+		where = append(where, "visible_ts <= ?")
+		args = append(args, opts.To.UnixNano()/int64(time.Millisecond))
+	}
+	if opts.LastID != "" {
+		where = append(where, "(visible_ts, id) < (SELECT visible_ts, id FROM transactions WHERE id = ?)")
+		args = append(args, opts.LastID)
+	}
+	query := "SELECT data FROM transactions"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY visible_ts DESC, id DESC"
+	if opts.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, opts.Limit)
+	}
+	rows, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	transactions := n26.N26Transactions{}
+	for rows.Next() {
+		var data string
+		err = rows.Scan(&data)
+		if err != nil {
+			return nil, err
+		}
+		transaction := n26.N26Transaction{}
+		err = json.Unmarshal([]byte(data), &transaction)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+	return &transactions, rows.Err()
+}
+
+// snapshot decodes the API response saved by the last sync into v
+func (a *archive) snapshot(ctx context.Context, name string, v interface{}) error {
+	var data string
+	err := a.db.QueryRowContext(ctx, `SELECT data FROM snapshots WHERE name = ?`, name).Scan(&data)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no %s in the archive, run n26 sync first", name)
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), v)
+}
+
+// Balance returns the balance of the last sync
+func (a *archive) Balance(ctx context.Context) (*n26.N26Account, error) {
+	balance := &n26.N26Account{}
+	return balance, a.snapshot(ctx, "balance", balance)
+}
+
+// AccountLimit returns the limits of the last sync
+func (a *archive) AccountLimit(ctx context.Context) (*n26.N26AccountLimit, error) {
+	limits := &n26.N26AccountLimit{}
+	return limits, a.snapshot(ctx, "limits", limits)
+}
+
+// AccountInfo returns the account information of the last sync
+func (a *archive) AccountInfo(ctx context.Context) (*n26.N26AccountInfo, error) {
+	info := &n26.N26AccountInfo{}
+	return info, a.snapshot(ctx, "info", info)
+}
+
+// Status returns the account status of the last sync
+func (a *archive) Status(ctx context.Context) (*n26.N26AccountStatus, error) {
+	status := &n26.N26AccountStatus{}
+	return status, a.snapshot(ctx, "status", status)
+}
+
+// Categories returns the categories of the last sync
+func (a *archive) Categories(ctx context.Context) (*n26.N26Categories, error) {
+	categories := &n26.N26Categories{}
+	return categories, a.snapshot(ctx, "categories", categories)
+}
+
+// Contacts returns the contacts of the last sync
+func (a *archive) Contacts(ctx context.Context) (*n26.N26Contacts, error) {
+	contacts := &n26.N26Contacts{}
+	return contacts, a.snapshot(ctx, "contacts", contacts)
+}
+
+// Statements returns the list of statements of the last sync
+func (a *archive) Statements(ctx context.Context) (*n26.N26BankStatements, error) {
+	statements := &n26.N26BankStatements{}
+	return statements, a.snapshot(ctx, "statements", statements)
+}
+
+// Cards returns the cards of the last sync
+func (a *archive) Cards(ctx context.Context) (*n26.N26Cards, error) {
+	cards := &n26.N26Cards{}
+	return cards, a.snapshot(ctx, "cards", cards)
+}
+
+// Savings returns the savings of the last sync
+func (a *archive) Savings(ctx context.Context) (*n26.N26Savings, error) {
+	savings := &n26.N26Savings{}
+	return savings, a.snapshot(ctx, "savings", savings)
+}
+
+// Spaces returns the spaces of the last sync
+func (a *archive) Spaces(ctx context.Context) (*n26.N26Spaces, error) {
+	spaces := &n26.N26Spaces{}
+	return spaces, a.snapshot(ctx, "spaces", spaces)
+}
+
+// Stats isn't archived
+func (a *archive) Stats(ctx context.Context, opts n26.StatsOptions) (*n26.N26Stats, error) {
+	return nil, errOffline
+}
+
+// Statement isn't archived
+func (a *archive) Statement(ctx context.Context, statementID string, format n26.StatementFormat, w io.Writer) error {
+	return errOffline
+}
+
+// BlockCard needs the API
+func (a *archive) BlockCard(ctx context.Context, cardID string) (*n26.N26CardV1, error) {
+	return nil, errOffline
+}
+
+// UnblockCard needs the API
+func (a *archive) UnblockCard(ctx context.Context, cardID string) (*n26.N26CardV1, error) {
+	return nil, errOffline
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/njuettner/n26"
+)
+
+const camtNamespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+
+// camtDocument is an ISO 20022 camt.053.001.08 bank to customer statement
+type camtDocument struct {
+	XMLName   xml.Name      `xml:"Document"`
+	Namespace string        `xml:"xmlns,attr"`
+	Statement camtStatement `xml:"BkToCstmrStmt"`
+}
+
+type camtStatement struct {
+	GroupHeader struct {
+		MsgID   string `xml:"MsgId"`
+		Created string `xml:"CreDtTm"`
+	} `xml:"GrpHdr"`
+	Statement struct {
+		ID      string `xml:"Id"`
+		Created string `xml:"CreDtTm"`
+		Period  struct {
+			From string `xml:"FrDtTm"`
+			To   string `xml:"ToDtTm"`
+		} `xml:"FrToDt"`
+		Account struct {
+			IBAN     string     `xml:"Id>IBAN"`
+			Currency string     `xml:"Ccy"`
+			Servicer *camtAgent `xml:"Svcr,omitempty"`
+		} `xml:"Acct"`
+		Balances []camtBalance `xml:"Bal"`
+		Entries  []camtEntry   `xml:"Ntry"`
+	} `xml:"Stmt"`
+}
+
+type camtAgent struct {
+	BIC string `xml:"FinInstnId>BICFI"`
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtBalance struct {
+	Type   string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount camtAmount `xml:"Amt"`
+	Sign   string     `xml:"CdtDbtInd"`
+	Date   string     `xml:"Dt>Dt"`
+}
+
+type camtEntry struct {
+	Amount      camtAmount `xml:"Amt"`
+	Sign        string     `xml:"CdtDbtInd"`
+	Status      string     `xml:"Sts>Cd"`
+	BookingDate *camtDate  `xml:"BookgDt,omitempty"`
+	ValueDate   camtDate   `xml:"ValDt"`
+	Reference   string     `xml:"AcctSvcrRef"`
+	Code        string     `xml:"BkTxCd>Prtry>Cd"`
+	Details     struct {
+		Transaction camtTransaction `xml:"TxDtls"`
+	} `xml:"NtryDtls"`
+}
+
+type camtTransaction struct {
+	References struct {
+		AccountServicer string `xml:"AcctSvcrRef"`
+		MandateID       string `xml:"MndtId,omitempty"`
+	} `xml:"Refs"`
+	Parties *camtParties `xml:"RltdPties,omitempty"`
+	Agents  *camtAgents  `xml:"RltdAgts,omitempty"`
+	Info    *camtInfo    `xml:"RmtInf,omitempty"`
+}
+
+type camtDate struct {
+	Date string `xml:"Dt"`
+}
+
+type camtParties struct {
+	Debtor          *camtParty   `xml:"Dbtr,omitempty"`
+	DebtorAccount   *camtAccount `xml:"DbtrAcct,omitempty"`
+	Creditor        *camtParty   `xml:"Cdtr,omitempty"`
+	CreditorAccount *camtAccount `xml:"CdtrAcct,omitempty"`
+}
+
+type camtParty struct {
+	Name string `xml:"Pty>Nm"`
+}
+
+type camtAccount struct {
+	IBAN string `xml:"Id>IBAN"`
+}
+
+type camtInfo struct {
+	Unstructured string `xml:"Ustrd"`
+}
+
+type camtAgents struct {
+	Debtor   *camtAgent `xml:"DbtrAgt,omitempty"`
+	Creditor *camtAgent `xml:"CdtrAgt,omitempty"`
+}
+
+// writeCAMT053 writes the statement as camt.053 XML, the partner of a
+// transaction is the creditor of payments and the debtor of incoming money
+func writeCAMT053(w io.Writer, s *statement, now time.Time) error {
+	currency := s.Account.Currency
+	doc := camtDocument{Namespace: camtNamespace}
+	doc.Statement.GroupHeader.MsgID = "N26-" + now.Format("20060102150405")
+	doc.Statement.GroupHeader.Created = camtDateTime(now)
+	stmt := &doc.Statement.Statement
+	stmt.ID = "N26-" + s.From.Format("20060102") + "-" + s.To.Format("20060102")
+	stmt.Created = camtDateTime(now)
+	stmt.Period.From = camtDateTime(s.From)
+	stmt.Period.To = camtDateTime(s.To)
+	stmt.Account.IBAN = s.Account.Iban
+	stmt.Account.Currency = currency
+	if s.Account.Bic != "" {
+		stmt.Account.Servicer = &camtAgent{BIC: s.Account.Bic}
+	}
+	stmt.Balances = []camtBalance{
+		camtBalanceOf("OPBD", s.OpeningBalance, currency, s.From),
+		camtBalanceOf("CLBD", s.ClosingBalance, currency, s.To),
+	}
+	for _, transaction := range s.Transactions {
+		stmt.Entries = append(stmt.Entries, camtEntryOf(transaction))
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err = enc.Encode(doc)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func camtBalanceOf(balanceType string, amount float64, currency string, date time.Time) camtBalance {
+	return camtBalance{
+		Type:   balanceType,
+		Amount: camtAmountOf(amount, currency),
+		Sign:   camtSign(amount),
+		Date:   date.Format("2006-01-02"),
+	}
+}
+
+func camtEntryOf(transaction n26.N26Transaction) camtEntry {
+	entry := camtEntry{
+		Amount:    camtAmountOf(transaction.Amount, transaction.CurrencyCode),
+		Sign:      camtSign(transaction.Amount),
+		Status:    "BOOK",
+		ValueDate: camtDate{millisToTime(transaction.VisibleTS).Format("2006-01-02")},
+		Reference: truncate(transaction.ID, 35),
+		Code:      transaction.Type,
+	}
+	if transaction.Pending {
+		entry.Status = "PDNG"
+	} else {
+		booked := transaction.Confirmed
+		if booked == 0 {
+			booked = transaction.VisibleTS
+		}
+		entry.BookingDate = &camtDate{millisToTime(booked).Format("2006-01-02")}
+	}
+	if entry.Code == "" {
+		entry.Code = "NTRF"
+	}
+
+	details := &entry.Details.Transaction
+	details.References.AccountServicer = entry.Reference
+	details.References.MandateID = truncate(transaction.MandateID, 35)
+	if transaction.ReferenceText != "" {
+		details.Info = &camtInfo{truncate(transaction.ReferenceText, 140)}
+	}
+	if transaction.PartnerName != "" || transaction.PartnerIban != "" {
+		var party *camtParty
+		var account *camtAccount
+		if transaction.PartnerName != "" {
+			party = &camtParty{truncate(transaction.PartnerName, 140)}
+		}
+		if transaction.PartnerIban != "" {
+			account = &camtAccount{transaction.PartnerIban}
+		}
+		if transaction.Amount < 0 {
+			details.Parties = &camtParties{Creditor: party, CreditorAccount: account}
+		} else {
+			details.Parties = &camtParties{Debtor: party, DebtorAccount: account}
+		}
+	}
+	if transaction.PartnerBic != "" {
+		details.Agents = &camtAgents{}
+		if transaction.Amount < 0 {
+			details.Agents.Creditor = &camtAgent{BIC: transaction.PartnerBic}
+		} else {
+			details.Agents.Debtor = &camtAgent{BIC: transaction.PartnerBic}
+		}
+	}
+	return entry
+}
+
+// camtAmountOf returns the absolute amount, the sign is the CdtDbtInd
+func camtAmountOf(amount float64, currency string) camtAmount {
+	return camtAmount{Currency: currency, Value: strconv.FormatFloat(math.Abs(amount), 'f', 2, 64)}
+}
+
+func camtSign(amount float64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+func camtDateTime(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05.000-07:00")
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteCAMT053ValidatesAgainstSchema(t *testing.T) {
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Skip("xmllint not installed")
+	}
+	buf := &bytes.Buffer{}
+	err = writeCAMT053(buf, testStatement(), time.Date(2019, time.April, 1, 8, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "n26")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "statement.xml")
+	err = ioutil.WriteFile(path, buf.Bytes(), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command(xmllint, "--noout", "--schema", filepath.Join("testdata", "camt.053.001.08.xsd"), path).CombinedOutput()
+	if err != nil {
+		t.Fatalf("camt.053 does not validate: %s\n%s", err, out)
+	}
+}
+
+func TestWriteCAMT053(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := writeCAMT053(buf, testStatement(), time.Date(2019, time.April, 1, 8, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := camtDocument{}
+	err = xml.Unmarshal(buf.Bytes(), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt := doc.Statement.Statement
+	if len(stmt.Balances) != 2 {
+		t.Fatalf("got %d balances, want 2", len(stmt.Balances))
+	}
+	for i, want := range []camtBalance{
+		{Type: "OPBD", Amount: camtAmount{"EUR", "100.00"}, Sign: "CRDT", Date: "2019-03-01"},
+		{Type: "CLBD", Amount: camtAmount{"EUR", "2486.65"}, Sign: "CRDT", Date: "2019-03-31"},
+	} {
+		if stmt.Balances[i] != want {
+			t.Errorf("balance %d = %+v, want %+v", i, stmt.Balances[i], want)
+		}
+	}
+	if len(stmt.Entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(stmt.Entries))
+	}
+	debit := stmt.Entries[1]
+	if debit.Amount.Value != "89.90" || debit.Sign != "DBIT" || debit.ValueDate.Date != "2019-03-12" {
+		t.Errorf("direct debit = %s %s on %s, want 89.90 DBIT on 2019-03-12", debit.Amount.Value, debit.Sign, debit.ValueDate.Date)
+	}
+	if mandate := debit.Details.Transaction.References.MandateID; mandate != "MANDATE-2019-0042" {
+		t.Errorf("mandate ID = %q, want MANDATE-2019-0042", mandate)
+	}
+	credit := stmt.Entries[2]
+	if credit.Sign != "CRDT" || credit.Details.Transaction.Parties.Debtor.Name != "ACME Corp." {
+		t.Errorf("incoming transfer = %s from %+v, want CRDT from ACME Corp.", credit.Sign, credit.Details.Transaction.Parties.Debtor)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"crypto/cipher"
+	"fmt"
+	"os"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/njuettner/n26"
+	"github.com/spf13/viper"
+)
+
+// Credentials is the content of the config file
+type Credentials struct {
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	// PasswordCommand prints the password, e.g. pass show n26
+	PasswordCommand string `yaml:"password_command,omitempty"`
+	// Keyring keeps the password and the OAuth token in the OS keyring
+	Keyring     bool    `yaml:"keyring,omitempty"`
+	DeviceToken string  `yaml:"device_token,omitempty"`
+	MFAType     string  `yaml:"mfa_type,omitempty"`
+	APIURL      string  `yaml:"api_url,omitempty"`
+	Journal     Journal `yaml:"journal,omitempty"`
+	// Encrypted holds username, password and device token after n26 init --encrypt
+	Encrypted *EncryptedCredentials `yaml:"encrypted,omitempty"`
+
+	// tokenCipher encrypts the token file of an encrypted config
+	tokenCipher cipher.AEAD
+}
+
+// Journal names the accounts used by the beancount and ledger export
+type Journal struct {
+	// Account is the N26 account, Assets:N26 by default
+	Account string `yaml:"account,omitempty"`
+	// Categories maps N26 categories like micro-v2-food-groceries to accounts
+	Categories map[string]string `yaml:"categories,omitempty"`
+}
+
+// NewConfig initializes the config file
+func NewConfig(username, password, mfaType string) (*Credentials, error) {
+	deviceToken, err := n26.NewDeviceToken()
+	if err != nil {
+		return nil, err
+	}
+	return &Credentials{
+		Username:    username,
+		Password:    password,
+		DeviceToken: deviceToken,
+		MFAType:     mfaType,
+	}, nil
+}
+
+// Config returns configuration from file to use N26 API
+func Config() (*n26.Client, error) {
+	credentials, err := readConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not read config, run n26 init first: %s", err)
+	}
+	if credentials.Encrypted != nil {
+		passphrase, err := readPassphrase(false)
+		if err != nil {
+			return nil, err
+		}
+		err = credentials.decrypt(passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newClient(credentials), nil
+}
+
+func readConfig() (*Credentials, error) {
+	config := viper.New()
+	config.SetConfigType("yaml")
+	config.SetConfigName("n26")
+	config.AddConfigPath("$HOME/.config")
+	err := config.ReadInConfig()
+	if err != nil {
+		return nil, err
+	}
+	credentials := &Credentials{
+		Username:        config.GetString("username"),
+		Password:        config.GetString("password"),
+		PasswordCommand: config.GetString("password_command"),
+		Keyring:         config.GetBool("keyring"),
+		DeviceToken:     config.GetString("device_token"),
+		MFAType:         config.GetString("mfa_type"),
+		APIURL:          config.GetString("api_url"),
+		Journal: Journal{
+			Account:    config.GetString("journal.account"),
+			Categories: config.GetStringMapString("journal.categories"),
+		},
+	}
+	if config.IsSet("encrypted") {
+		credentials.Encrypted = &EncryptedCredentials{
+			KDF:   config.GetString("encrypted.kdf"),
+			N:     config.GetInt("encrypted.scrypt_n"),
+			R:     config.GetInt("encrypted.scrypt_r"),
+			P:     config.GetInt("encrypted.scrypt_p"),
+			Salt:  config.GetString("encrypted.salt"),
+			Nonce: config.GetString("encrypted.nonce"),
+			Data:  config.GetString("encrypted.data"),
+		}
+	}
+	return credentials, nil
+}
+
+func newClient(credentials *Credentials) *n26.Client {
+	policy := n26.DefaultRetryPolicy
+	policy.MaxAttempts = *retries + 1
+	opts := []n26.Option{
+		n26.WithUserAgent("n26-cli/" + version),
+		n26.WithRetryPolicy(policy),
+		n26.WithTimeout(*timeout),
+	}
+	if *apiURL != "" {
+		opts = append(opts, n26.WithBaseURL(*apiURL))
+	} else if credentials.APIURL != "" {
+		opts = append(opts, n26.WithBaseURL(credentials.APIURL))
+	}
+	client := n26.NewClient(credentials.Username, "", opts...)
+	client.PasswordFunc = credentials.lookupPassword
+	client.DeviceToken = credentials.DeviceToken
+	client.MFAType = credentials.MFAType
+	client.MFAPrompt = promptMFA
+	if credentials.Keyring {
+		client.TokenStore = &keyringTokenStore{user: credentials.Username}
+		return client
+	}
+	tokenPath, err := homedir.Expand(tokenFilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not find token file, %s", err)
+		return client
+	}
+	if credentials.tokenCipher != nil {
+		client.TokenStore = &encryptedTokenStore{path: tokenPath, aead: credentials.tokenCipher}
+		return client
+	}
+	client.TokenStore = n26.NewFileTokenStore(tokenPath)
+	return client
+}
+
+func promptMFA(challengeType string) (string, error) {
+	if challengeType != n26.MFATypeSMS {
+		fmt.Fprintln(os.Stderr, "Please confirm the login in your N26 app")
+		return "", nil
+	}
+	var otp string
+	fmt.Fprint(os.Stderr, "N26 SMS code: ")
+	_, err := fmt.Scanln(&otp)
+	return otp, err
+}
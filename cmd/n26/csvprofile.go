@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/njuettner/n26"
+)
+
+// csvProfile is the CSV layout a budgeting tool imports
+type csvProfile struct {
+	Header []string
+	Row    func(transaction n26.N26Transaction, categories map[string]string) []string
+}
+
+var csvProfileNames = []string{"ynab", "firefly"}
+
+var csvProfiles = map[string]csvProfile{
+	// ynab splits the amount into positive outflow and inflow columns
+	"ynab": {
+		Header: []string{"Date", "Payee", "Memo", "Outflow", "Inflow"},
+		Row: func(transaction n26.N26Transaction, categories map[string]string) []string {
+			var outflow, inflow string
+			if transaction.Amount < 0 {
+				outflow = formatAmount(-transaction.Amount)
+			} else {
+				inflow = formatAmount(transaction.Amount)
+			}
+			return []string{
+				millisToTime(transaction.VisibleTS).Format("01/02/2006"),
+				transaction.PartnerName,
+				transaction.ReferenceText,
+				outflow,
+				inflow,
+			}
+		},
+	},
+	// firefly uses a signed amount and the N26 ID as external ID
+	"firefly": {
+		Header: []string{"date", "amount", "currency_code", "description", "opposing_name", "opposing_iban",
+			"opposing_bic", "category", "external_id", "pending"},
+		Row: func(transaction n26.N26Transaction, categories map[string]string) []string {
+			description := transaction.ReferenceText
+			if description == "" {
+				description = transaction.PartnerName
+			}
+			return []string{
+				millisToTime(transaction.VisibleTS).Format("2006-01-02"),
+				formatAmount(transaction.Amount),
+				transaction.CurrencyCode,
+				description,
+				transaction.PartnerName,
+				transaction.PartnerIban,
+				transaction.PartnerBic,
+				categories[transaction.Category],
+				transaction.ID,
+				strconv.FormatBool(transaction.Pending),
+			}
+		},
+	},
+}
+
+// writeCSVProfile writes the statement transactions in the layout of profile
+func writeCSVProfile(w io.Writer, s *statement, profile csvProfile, categories map[string]string) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(profile.Header)
+	if err != nil {
+		return err
+	}
+	for _, transaction := range s.Transactions {
+		err = cw.Write(profile.Row(transaction, categories))
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var relativeDate = regexp.MustCompile(`^(\d+)([hdwmy])$`)
+
+// parseDate understands dates like 2019-03-01, 2019-03, RFC 3339 timestamps,
+// today and yesterday. With end set, a date without time is the end of the
+// day or month instead of its start.
+func parseDate(value string, end bool, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(value) {
+	case "now":
+		return now, nil
+	case "today":
+		return dayBoundary(today, end), nil
+	case "yesterday":
+		return dayBoundary(today.AddDate(0, 0, -1), end), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04", value, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return dayBoundary(t, end), nil
+	}
+	if t, err := time.ParseInLocation("2006-01", value, now.Location()); err == nil {
+		if end {
+			return t.AddDate(0, 1, 0).Add(-time.Millisecond), nil
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("could not parse date %q, use e.g. 2019-03-01, 2019-03 or yesterday", value)
+}
+
+// parseSince understands durations like 12h, 30d, 2w, 6m and 1y before now
+func parseSince(value string, now time.Time) (time.Time, error) {
+	match := relativeDate.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return time.Time{}, fmt.Errorf("could not parse %q, use e.g. 12h, 30d, 2w, 6m or 1y", value)
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch match[2] {
+	case "h":
+		return now.Add(-time.Duration(n) * time.Hour), nil
+	case "d":
+		return now.AddDate(0, 0, -n), nil
+	case "w":
+		return now.AddDate(0, 0, -7*n), nil
+	case "m":
+		return now.AddDate(0, -n, 0), nil
+	default:
+		return now.AddDate(-n, 0, 0), nil
+	}
+}
+
+// parseDateRange turns the --from, --to and --since flags into a time range,
+// all errors are usage errors
+func parseDateRange(from, to, since string, now time.Time) (time.Time, time.Time, error) {
+	var start, end time.Time
+	var err error
+	if since != "" && from != "" {
+		return start, end, usageError{fmt.Errorf("--since and --from can not be used together")}
+	}
+	if since != "" {
+		start, err = parseSince(since, now)
+	} else if from != "" {
+		start, err = parseDate(from, false, now)
+	}
+	if err != nil {
+		return start, end, usageError{err}
+	}
+	if to != "" {
+		end, err = parseDate(to, true, now)
+		if err != nil {
+			return start, end, usageError{err}
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		return start, end, usageError{fmt.Errorf("--to is before --from")}
+	}
+	return start, end, nil
+}
+
+func dayBoundary(day time.Time, end bool) time.Time {
+	if end {
+		return day.AddDate(0, 0, 1).Add(-time.Millisecond)
+	}
+	return day
+}
+
+// millisToTime converts a timestamp in milliseconds as used by the N26 API
+func millisToTime(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/howeyc/gopass"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/oauth2"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// passphraseEnv holds the passphrase of an encrypted config file
+	passphraseEnv = "N26_PASSPHRASE"
+
+	kdfScrypt = "scrypt"
+	// scrypt parameters recommended for interactive logins
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var errWrongPassphrase = errors.New("could not decrypt config, wrong passphrase")
+
+// EncryptedCredentials is the credentials section of the config encrypted
+// with AES-256-GCM, the key is derived from a passphrase with scrypt
+type EncryptedCredentials struct {
+	KDF   string `yaml:"kdf"`
+	N     int    `yaml:"scrypt_n"`
+	R     int    `yaml:"scrypt_r"`
+	P     int    `yaml:"scrypt_p"`
+	Salt  string `yaml:"salt"`
+	Nonce string `yaml:"nonce"`
+	Data  string `yaml:"data"`
+}
+
+// secretCredentials is the part of the config that is encrypted
+type secretCredentials struct {
+	Username    string `yaml:"username"`
+	Password    string `yaml:"password,omitempty"`
+	DeviceToken string `yaml:"device_token,omitempty"`
+}
+
+// encrypt moves username, password and device token into the encrypted
+// section, the token file is encrypted with the same key
+func (c *Credentials) encrypt(passphrase []byte) error {
+	plaintext, err := yaml.Marshal(secretCredentials{
+		Username:    c.Username,
+		Password:    c.Password,
+		DeviceToken: c.DeviceToken,
+	})
+	if err != nil {
+		return err
+	}
+	e := &EncryptedCredentials{KDF: kdfScrypt, N: scryptN, R: scryptR, P: scryptP}
+	salt := make([]byte, 16)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return err
+	}
+	aead, err := e.cipher(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return err
+	}
+	e.Salt = base64.StdEncoding.EncodeToString(salt)
+	e.Nonce = base64.StdEncoding.EncodeToString(nonce)
+	e.Data = base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, plaintext, nil))
+	c.Encrypted = e
+	c.tokenCipher = aead
+	c.Username, c.Password, c.DeviceToken = "", "", ""
+	return nil
+}
+
+// decrypt restores username, password and device token of an encrypted config
+func (c *Credentials) decrypt(passphrase []byte) error {
+	e := c.Encrypted
+	salt, err := base64.StdEncoding.DecodeString(e.Salt)
+	if err != nil {
+		return fmt.Errorf("invalid salt of encrypted config: %s", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(e.Nonce)
+	if err != nil {
+		return fmt.Errorf("invalid nonce of encrypted config: %s", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(e.Data)
+	if err != nil {
+		return fmt.Errorf("invalid data of encrypted config: %s", err)
+	}
+	aead, err := e.cipher(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return fmt.Errorf("invalid nonce of encrypted config")
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return errWrongPassphrase
+	}
+	secrets := secretCredentials{}
+	err = yaml.Unmarshal(plaintext, &secrets)
+	if err != nil {
+		return err
+	}
+	c.Username = secrets.Username
+	c.Password = secrets.Password
+	c.DeviceToken = secrets.DeviceToken
+	c.Encrypted = nil
+	c.tokenCipher = aead
+	return nil
+}
+
+func (e *EncryptedCredentials) cipher(passphrase, salt []byte) (cipher.AEAD, error) {
+	if e.KDF != kdfScrypt {
+		return nil, fmt.Errorf("unsupported key derivation %q in encrypted config", e.KDF)
+	}
+	key, err := scrypt.Key(passphrase, salt, e.N, e.R, e.P, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// readPassphrase reads the passphrase of the config from N26_PASSPHRASE,
+// --passphrase-fd or the terminal, confirm asks twice on the terminal
+func readPassphrase(confirm bool) ([]byte, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	if *passphraseFD >= 0 {
+		f := os.NewFile(uintptr(*passphraseFD), "passphrase")
+		if f == nil {
+			return nil, fmt.Errorf("invalid --passphrase-fd %d", *passphraseFD)
+		}
+		defer f.Close()
+		line, err := bufio.NewReader(f).ReadString('\n')
+		if err != nil && line == "" {
+			return nil, fmt.Errorf("could not read passphrase from file descriptor %d: %s", *passphraseFD, err)
+		}
+		return nonEmpty([]byte(strings.TrimRight(line, "\r\n")))
+	}
+	passphrase, err := gopass.GetPasswdPrompt("Config passphrase: ", true, os.Stdin, os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if confirm {
+		repeated, err := gopass.GetPasswdPrompt("Repeat passphrase: ", true, os.Stdin, os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		if string(repeated) != string(passphrase) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+	return nonEmpty(passphrase)
+}
+
+func nonEmpty(passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("empty passphrase")
+	}
+	return passphrase, nil
+}
+
+// encryptedTokenStore keeps the OAuth token in a file encrypted with the key
+// of the config, the refresh token gives access without the password
+type encryptedTokenStore struct {
+	path string
+	aead cipher.AEAD
+}
+
+// encryptedToken is the content of the token file
+type encryptedToken struct {
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+func (s *encryptedTokenStore) Token() (*oauth2.Token, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	encrypted := encryptedToken{}
+	err = json.Unmarshal(data, &encrypted)
+	if err != nil || len(encrypted.Nonce) != s.aead.NonceSize() {
+		return nil, fmt.Errorf("could not read encrypted token from %s, run n26 init again", s.path)
+	}
+	plaintext, err := s.aead.Open(nil, encrypted.Nonce, encrypted.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt token from %s, run n26 init again", s.path)
+	}
+	token := &oauth2.Token{}
+	err = json.Unmarshal(plaintext, token)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *encryptedTokenStore) SetToken(token *oauth2.Token) error {
+	plaintext, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(encryptedToken{Nonce: nonce, Data: s.aead.Seal(nil, nonce, plaintext, nil)})
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(s.path), 0700)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, data, 0600)
+}
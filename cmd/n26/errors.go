@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/njuettner/n26"
+)
+
+// exit status of n26, documented in the README
+const (
+	exitError     = 1
+	exitUsage     = 2
+	exitAuth      = 3
+	exitNetwork   = 4
+	exitAPIClient = 5
+	exitAPIServer = 6
+)
+
+// usageError is returned for invalid arguments and flags
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+// exitStatus maps err to the exit status of n26
+func exitStatus(err error) int {
+	var usageErr usageError
+	if errors.As(err, &usageErr) {
+		return exitUsage
+	}
+	if n26.IsUnauthorized(err) || n26.IsMFARequired(err) || errors.Is(err, errWrongPassphrase) {
+		return exitAuth
+	}
+	var apiErr *n26.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode >= http.StatusInternalServerError {
+			return exitAPIServer
+		}
+		return exitAPIClient
+	}
+	if isNetworkError(err) {
+		return exitNetwork
+	}
+	return exitError
+}
+
+// isNetworkError reports whether the N26 API could not be reached in time
+func isNetworkError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return errors.As(err, &opErr) || errors.As(err, &dnsErr)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/njuettner/n26"
+)
+
+// statement is the data the export formats are written from
+type statement struct {
+	Account *n26.N26Account
+	// Transactions are sorted oldest first
+	Transactions n26.N26Transactions
+	// From and To is the exported period, neither is zero
+	From time.Time
+	To   time.Time
+	// OpeningBalance and ClosingBalance are the booked balance before and
+	// after the period, calculated back from the current bank balance
+	OpeningBalance float64
+	ClosingBalance float64
+}
+
+// loadStatement fetches the account and all transactions between from and
+// to, pending transactions are left out unless pending is set
+func loadStatement(ctx context.Context, client n26.N26Interface, from, to time.Time, pending bool) (*statement, error) {
+	account, err := client.Balance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if to.IsZero() || to.After(now) {
+		to = now
+	}
+	// transactions after the period are needed to get its closing balance
+	transactions, err := n26.NewTransactionsIterator(ctx, client, n26.TransactionsOptions{From: from, To: now}).All()
+	if err != nil {
+		return nil, err
+	}
+	s := &statement{Account: account, From: from, To: to}
+	toMillis := to.UnixNano() / int64(time.Millisecond)
+	closing := cents(account.BankBalance)
+	var period int64
+	for i := len(*transactions) - 1; i >= 0; i-- {
+		transaction := (*transactions)[i]
+		if transaction.VisibleTS > toMillis {
+			if !transaction.Pending {
+				closing -= cents(transaction.Amount)
+			}
+			continue
+		}
+		if transaction.Pending {
+			if !pending {
+				continue
+			}
+			// included pending transactions count as if they were booked
+			closing += cents(transaction.Amount)
+		}
+		period += cents(transaction.Amount)
+		s.Transactions = append(s.Transactions, transaction)
+	}
+	s.ClosingBalance = float64(closing) / 100
+	s.OpeningBalance = float64(closing-period) / 100
+	if s.From.IsZero() && len(s.Transactions) > 0 {
+		s.From = millisToTime(s.Transactions[0].VisibleTS)
+	}
+	// without transactions the opening balance is the balance at the end
+	if s.From.IsZero() {
+		s.From = s.To
+	}
+	return s, nil
+}
+
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// categoryNames maps category IDs like micro-v2-food-groceries to their names
+func categoryNames(ctx context.Context, client n26.N26Interface) (map[string]string, error) {
+	categories, err := client.Categories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]string{}
+	for _, category := range *categories {
+		names[category.ID] = category.Name
+	}
+	return names, nil
+}
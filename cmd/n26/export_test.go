@@ -0,0 +1,65 @@
+package main
+
+import (
+	"time"
+
+	"github.com/njuettner/n26"
+)
+
+// testStatement returns a statement with a payment, a direct debit and an
+// incoming transfer, the timestamps are at noon UTC to get the same dates in
+// every time zone
+func testStatement() *statement {
+	day := func(d int) int64 {
+		return time.Date(2019, time.March, d, 12, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+	}
+	return &statement{
+		Account: &n26.N26Account{
+			Iban:        "DE89370400440532013000",
+			Bic:         "NTSBDEB1XXX",
+			Currency:    "EUR",
+			BankBalance: 1234.56,
+		},
+		Transactions: n26.N26Transactions{
+			{
+				ID:           "2f4a4c1e-6b0d-4b4e-9a51-6f0c3d1f0a01",
+				Type:         "PT",
+				Amount:       -23.45,
+				CurrencyCode: "EUR",
+				VisibleTS:    day(4),
+				Confirmed:    day(5),
+				PartnerName:  "Bäckerei Müller & Söhne",
+				Category:     "micro-v2-food-groceries",
+			},
+			{
+				ID:            "8c1d2e3f-4a5b-4c6d-8e7f-901a2b3c4d02",
+				Type:          "DD",
+				Amount:        -89.9,
+				CurrencyCode:  "EUR",
+				VisibleTS:     day(12),
+				PartnerName:   "Stadtwerke Großstadt GmbH",
+				PartnerIban:   "DE02120300000000202051",
+				PartnerBic:    "BYLADEM1001",
+				MandateID:     "MANDATE-2019-0042",
+				Category:      "micro-v2-household-utilities",
+				ReferenceText: "Abschlag Strom und Gas März 2019 Vertragskonto 4711 0815 Zählernummer 12345678 Kundennummer 99887766 – bitte nicht überweisen, wird abgebucht",
+			},
+			{
+				ID:            "c3b2a190-8f7e-4d6c-b5a4-93827160fe03",
+				Type:          "CT",
+				Amount:        2500,
+				CurrencyCode:  "EUR",
+				VisibleTS:     day(28),
+				PartnerName:   "ACME Corp.",
+				PartnerIban:   "DE44500105175407324931",
+				PartnerBic:    "INGDDEFFXXX",
+				Category:      "micro-v2-income",
+				ReferenceText: "Gehalt 03/2019",
+			},
+		},
+		From:           time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2019, time.March, 31, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: 100,
+		ClosingBalance: 2486.65,
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/njuettner/n26"
+)
+
+const (
+	defaultJournalAccount = "Assets:N26"
+	openingBalanceAccount = "Equity:Opening-Balances"
+	journalDateFormat     = "2006-01-02"
+)
+
+// journalAccount returns the account of the other side of a transaction,
+// without a configured account it is derived from the N26 category, e.g.
+// micro-v2-food-groceries is Expenses:FoodGroceries
+func journalAccount(j Journal, transaction n26.N26Transaction) string {
+	if account, ok := j.Categories[transaction.Category]; ok {
+		return account
+	}
+	root := "Expenses"
+	if transaction.Amount > 0 {
+		root = "Income"
+	}
+	name := ""
+	for _, part := range strings.Split(strings.TrimPrefix(transaction.Category, "micro-v2-"), "-") {
+		if part != "" {
+			name += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	if name == "" {
+		name = "Uncategorized"
+	}
+	return root + ":" + name
+}
+
+func (j Journal) account() string {
+	if j.Account == "" {
+		return defaultJournalAccount
+	}
+	return j.Account
+}
+
+// writeBeancount writes the statement as beancount journal, the N26
+// transaction ID is kept as n26-id metadata to find duplicates. The opening
+// balance is padded from Equity:Opening-Balances, it is checked by the
+// balance assertion at the end.
+func writeBeancount(w io.Writer, s *statement, j Journal) error {
+	account := j.account()
+	currency := s.Account.Currency
+	from := s.From.Format(journalDateFormat)
+	_, err := fmt.Fprintf(w, "%s open %s %s\n", from, account, currency)
+	if err != nil {
+		return err
+	}
+	for _, other := range append([]string{openingBalanceAccount}, journalAccounts(j, s.Transactions)...) {
+		_, err = fmt.Fprintf(w, "%s open %s\n", from, other)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "\n%s pad %s %s\n\n", from, account, openingBalanceAccount)
+	if err != nil {
+		return err
+	}
+	for _, transaction := range s.Transactions {
+		flag := "*"
+		if transaction.Pending {
+			flag = "!"
+		}
+		_, err = fmt.Fprintf(w, "%s %s %s %s\n  n26-id: %s\n  %s  %s %s\n  %s\n\n",
+			millisToTime(transaction.VisibleTS).Format(journalDateFormat), flag,
+			beancountString(transaction.PartnerName), beancountString(transaction.ReferenceText),
+			beancountString(transaction.ID),
+			account, formatAmount(transaction.Amount), transaction.CurrencyCode,
+			journalAccount(j, transaction))
+		if err != nil {
+			return err
+		}
+	}
+	// balance assertions apply at the start of the day
+	_, err = fmt.Fprintf(w, "%s balance %s %s %s\n",
+		s.To.AddDate(0, 0, 1).Format(journalDateFormat), account, formatAmount(s.ClosingBalance), currency)
+	return err
+}
+
+// writeLedger writes the statement as ledger/hledger journal, the N26
+// transaction ID is kept as n26-id tag to find duplicates
+func writeLedger(w io.Writer, s *statement, j Journal) error {
+	account := j.account()
+	currency := s.Account.Currency
+	// a posting without amount but with = is a balance assignment
+	_, err := fmt.Fprintf(w, "%s * Opening balance\n    %s  = %s %s\n    %s\n\n",
+		s.From.Format(journalDateFormat), account, formatAmount(s.OpeningBalance), currency, openingBalanceAccount)
+	if err != nil {
+		return err
+	}
+	for _, transaction := range s.Transactions {
+		flag := "*"
+		if transaction.Pending {
+			flag = "!"
+		}
+		description := transaction.PartnerName
+		if transaction.ReferenceText != "" {
+			description += " | " + transaction.ReferenceText
+		}
+		_, err = fmt.Fprintf(w, "%s %s %s\n    ; n26-id: %s\n    %s  %s %s\n    %s\n\n",
+			millisToTime(transaction.VisibleTS).Format(journalDateFormat), flag,
+			ledgerText(description), transaction.ID,
+			account, formatAmount(transaction.Amount), transaction.CurrencyCode,
+			journalAccount(j, transaction))
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "%s * Closing balance\n    %s  0 %s = %s %s\n",
+		s.To.Format(journalDateFormat), account, currency, formatAmount(s.ClosingBalance), currency)
+	return err
+}
+
+// journalAccounts returns the sorted accounts of the other side of transactions
+func journalAccounts(j Journal, transactions n26.N26Transactions) []string {
+	seen := map[string]bool{}
+	var accounts []string
+	for _, transaction := range transactions {
+		account := journalAccount(j, transaction)
+		if !seen[account] {
+			seen[account] = true
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Strings(accounts)
+	return accounts
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+func beancountString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ", "\r", " ").Replace(s) + `"`
+}
+
+// ledgerText keeps descriptions on one line, a ; would start a comment
+func ledgerText(s string) string {
+	return strings.NewReplacer("\n", " ", "\r", " ", ";", ",").Replace(s)
+}
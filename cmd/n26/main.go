@@ -0,0 +1,604 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/howeyc/gopass"
+	"github.com/mitchellh/go-homedir"
+	"github.com/njuettner/n26"
+	"github.com/njuettner/n26/n26test"
+	"github.com/zalando/go-keyring"
+	"gopkg.in/alecthomas/kingpin.v2"
+	"gopkg.in/yaml.v2"
+)
+
+const defaultTransactions = 5
+
+var (
+	version            = "dev"
+	commit             = "none"
+	date               = "unknown"
+	app                = kingpin.New("n26", "A command-line to interact with your N26 bank account")
+	apiURL             = app.Flag("api-url", "Base URL of the N26 API").String()
+	timeout            = app.Flag("timeout", "Maximum duration of a single request to the N26 API, 0 waits forever").Default("2m").Duration()
+	output             = app.Flag("output", "Output format: table, json, yaml, csv or tsv").Short('o').Default(outputTable).Enum(outputFormats...)
+	outputColumns      = app.Flag("columns", "Comma separated fields to show as columns, e.g. partnerName,amount,referenceText").String()
+	outputSortBy       = app.Flag("sort-by", "Field to sort rows by, prefix with - for descending order").String()
+	outputTemplate     = app.Flag("template", "Go text/template applied to the full API response").String()
+	retries            = app.Flag("retries", "Number of retries for failed read requests").Default("3").Int()
+	offline            = app.Flag("offline", "Read from the archive of n26 sync instead of the N26 API").Bool()
+	archiveFile        = app.Flag("archive", "Path of the transaction archive").Default("~/.config/n26-archive.db").String()
+	passphraseFD       = app.Flag("passphrase-fd", "Read the passphrase of an encrypted config from this file descriptor").Default("-1").Int()
+	initialize         = app.Command("init", "Setup the configuration to use N26 CLI")
+	initMFA            = initialize.Flag("mfa", "Second factor to confirm the login: app or sms").Default("app").Enum("app", "sms")
+	initKeyring        = initialize.Flag("keyring", "Store the password and the login token in the OS keyring").Bool()
+	initPasswordCmd    = initialize.Flag("password-command", "Command printing the password instead of storing it, e.g. 'pass show n26'").String()
+	initEncrypt        = initialize.Flag("encrypt", "Encrypt the credentials in the config file with a passphrase").Bool()
+	categories         = app.Command("categories", "Show N26 categories")
+	transactions       = app.Command("transactions", "Show N26 latest transactions (Number by Default: 5)")
+	transactionsNumber = transactions.Arg("amount", "Number of transactions, by default 5 or all within --from/--to/--since").Int()
+	transactionsAll    = transactions.Flag("all", "Show every transaction since the account was opened").Bool()
+	transactionsFrom   = transactions.Flag("from", "Show transactions since this date, e.g. 2019-03-01, 2019-03 or yesterday").String()
+	transactionsTo     = transactions.Flag("to", "Show transactions until the end of this date").String()
+	transactionsSince  = transactions.Flag("since", "Show transactions of the last hours, days, weeks, months or years, e.g. 30d").String()
+	balance            = app.Command("balance", "Show N26 balance")
+	contacts           = app.Command("contacts", "Show N26 contacts")
+	account            = app.Command("account", "Show N26 account")
+	statements         = app.Command("statement", "Get N26 statement, will be saved as PDF or CSV files")
+	savings            = app.Command("savings", "Show N26 savings and investments")
+	statementID        = statements.Arg("statementID", "statement-YEAR-MONTH, e.g. statement-2017-05").String()
+	statementAll       = statements.Flag("all", "Download all statements").Bool()
+	statementYear      = statements.Flag("year", "Download the statements of a year").Int()
+	statementFrom      = statements.Flag("from", "Download the statements since this month, e.g. 2019-01").String()
+	statementTo        = statements.Flag("to", "Download the statements until this month").String()
+	statementOutDir    = statements.Flag("out-dir", "Directory the statements are saved to").Default(".").String()
+	statementFormat    = statements.Flag("format", "File format: pdf or csv").Default("pdf").Enum("pdf", "csv")
+	statementParallel  = statements.Flag("concurrency", "Number of statements downloaded at the same time").Default("4").Int()
+	info               = account.Command("info", "Show N26 account information")
+	limit              = account.Command("limit", "Show N26 account limit")
+	stats              = account.Command("stats", "Show N26 account statistics")
+	statsFrom          = stats.Flag("from", "Start of the statistics, by default 30 days ago").String()
+	statsTo            = stats.Flag("to", "End of the statistics, by default now").String()
+	statsSlices        = stats.Flag("slices", "Number of time slices").Default("25").Int()
+	statsType          = stats.Flag("type", "acct for the balance over time, cat for the spending per category").Default(n26.StatsAccount).Enum(n26.StatsAccount, n26.StatsCategory)
+	status             = account.Command("status", "Show N26 account status")
+	cards              = app.Command("cards", "Show N26 cards")
+	blockCard          = app.Command("block-card", "Block N26 card")
+	blockCardID        = blockCard.Arg("cardID", "N26 Card ID").Required().String()
+	unblockCard        = app.Command("unblock-card", "Unblock N26 card")
+	unblockCardID      = unblockCard.Arg("cardID", "N26 Card ID").Required().String()
+	spaces             = app.Command("spaces", "Show N26 spaces")
+	syncArchive        = app.Command("sync", "Download new transactions into the local archive")
+	export             = app.Command("export", "Export transactions for finance and accounting software")
+	exportFrom         = export.Flag("from", "Export transactions since this date, by default the whole history").String()
+	exportTo           = export.Flag("to", "Export transactions until the end of this date").String()
+	exportSince        = export.Flag("since", "Export transactions of the last hours, days, weeks, months or years, e.g. 30d").String()
+	exportPending      = export.Flag("pending", "Include pending transactions").Bool()
+	exportOFX          = export.Command("ofx", "Export an OFX statement for GnuCash, KMyMoney and others")
+	exportBeancount    = export.Command("beancount", "Export a beancount journal")
+	exportLedger       = export.Command("ledger", "Export a ledger/hledger journal")
+	exportCAMT053      = export.Command("camt053", "Export an ISO 20022 camt.053 bank statement")
+	exportMT940        = export.Command("mt940", "Export a SWIFT MT940 bank statement")
+	exportQIF          = export.Command("qif", "Export a QIF file for budgeting tools")
+	exportCSV          = export.Command("csv", "Export a CSV file in the layout of a budgeting tool")
+	exportCSVProfile   = exportCSV.Flag("profile", "CSV layout: ynab or firefly").Default("ynab").Enum(csvProfileNames...)
+	dev                = app.Command("dev", "Tools for developing against the N26 API")
+	fakeServer         = dev.Command("fake-server", "Run a fake N26 API with seeded data")
+	fakeServerListen   = fakeServer.Flag("listen", "Address to listen on").Default("127.0.0.1:8026").String()
+	fakeServerMFA      = fakeServer.Flag("mfa", "Require a two-factor login").Bool()
+	client             n26.N26Interface
+	configFilePath     = "~/.config/n26.yaml"
+	tokenFilePath      = "~/.config/n26-token.json"
+)
+
+func main() {
+	app.Version(version).Author("Nick Jüttner")
+
+	command, err := app.Parse(os.Args[1:])
+	if err != nil {
+		app.Errorf("%s, try --help", err)
+		os.Exit(exitUsage)
+	}
+	err = run(command)
+	if err != nil {
+		app.Errorf("%s", err)
+		os.Exit(exitStatus(err))
+	}
+}
+
+// run executes the parsed command, its error decides the exit status
+func run(command string) error {
+	ctx := context.Background()
+	if command != initialize.FullCommand() && command != fakeServer.FullCommand() {
+		if *offline {
+			if command == syncArchive.FullCommand() {
+				return usageError{fmt.Errorf("sync needs the N26 API, it can't run with --offline")}
+			}
+			archive, err := openArchiveReadOnly(archivePath())
+			if err != nil {
+				return err
+			}
+			defer archive.Close()
+			client = archive
+		} else {
+			var err error
+			client, err = Config()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	switch command {
+	case initialize.FullCommand():
+		var email string
+		fmt.Print("N26 Email: ")
+		fmt.Scanln(&email)
+		var password string
+		if *initPasswordCmd == "" && os.Getenv(passwordEnv) == "" {
+			fmt.Print("N26 Password: ")
+			pass, err := gopass.GetPasswdMasked()
+			if err != nil {
+				return err
+			}
+			password = string(pass)
+		}
+		mfaType := n26.MFATypeApp
+		if *initMFA == "sms" {
+			mfaType = n26.MFATypeSMS
+		}
+		cfg, err := NewConfig(email, password, mfaType)
+		if err != nil {
+			return err
+		}
+		cfg.APIURL = *apiURL
+		cfg.PasswordCommand = *initPasswordCmd
+		if *initKeyring {
+			cfg.Keyring = true
+			err = (&keyringTokenStore{user: email}).deleteToken()
+			if err != nil {
+				return err
+			}
+		}
+		if *initKeyring && cfg.PasswordCommand == "" {
+			password, err = cfg.lookupPassword()
+			if err != nil {
+				return err
+			}
+			err = keyring.Set(keyringService, email, password)
+			if err != nil {
+				return fmt.Errorf("could not store password in keyring: %s", err)
+			}
+			cfg.Password = ""
+		}
+		// the client logs in with cfg, only the written config is encrypted
+		stored := *cfg
+		if *initEncrypt {
+			passphrase, err := readPassphrase(true)
+			if err != nil {
+				return err
+			}
+			err = stored.encrypt(passphrase)
+			if err != nil {
+				return err
+			}
+			cfg.tokenCipher = stored.tokenCipher
+		}
+		data, err := yaml.Marshal(stored)
+		if err != nil {
+			return err
+		}
+		filePath, err := homedir.Expand(configFilePath)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(filePath, data, 0600)
+		if err != nil {
+			return err
+		}
+		tokenPath, err := homedir.Expand(tokenFilePath)
+		if err != nil {
+			return err
+		}
+		err = os.Remove(tokenPath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return newClient(cfg).Login(ctx)
+
+	case fakeServer.FullCommand():
+		api := n26test.NewHandler()
+		api.MFA = *fakeServerMFA
+		fmt.Fprintf(os.Stderr, "Fake N26 API on http://%s, login with %s / %s\n", *fakeServerListen, api.Email, api.Password)
+		return http.ListenAndServe(*fakeServerListen, api)
+
+	case transactions.FullCommand():
+		from, to, err := parseDateRange(*transactionsFrom, *transactionsTo, *transactionsSince, time.Now())
+		if err != nil {
+			return err
+		}
+		opts := n26.TransactionsOptions{Limit: *transactionsNumber, From: from, To: to}
+		var transactions *n26.N26Transactions
+		if opts.Limit == 0 && (*transactionsAll || !from.IsZero() || !to.IsZero()) {
+			transactions, err = n26.NewTransactionsIterator(ctx, client, opts).All()
+		} else {
+			if opts.Limit == 0 {
+				opts.Limit = defaultTransactions
+			}
+			transactions, err = client.Transactions(ctx, opts)
+		}
+		if err != nil {
+			return err
+		}
+		return render(view{
+			Model: transactions,
+			Rows:  transactions,
+			Columns: []column{
+				{Header: "Partner Name", Field: "partnerName"},
+				amountColumn("Amount", "amount", "currencyCode", -1),
+				{Header: "Category", Field: "category", Format: func(row reflect.Value) string {
+					return strings.Replace(row.FieldByName("Category").String(), "micro-v2-", "", -1)
+				}},
+			},
+		})
+
+	case syncArchive.FullCommand():
+		archive, err := openArchive(archivePath())
+		if err != nil {
+			return err
+		}
+		defer archive.Close()
+		result, err := archive.sync(ctx, client)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(result.FailedSnapshots))
+		for name := range result.FailedSnapshots {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(os.Stderr, "n26: warning: could not update %s, the archive keeps the previous data: %s\n", name, result.FailedSnapshots[name])
+		}
+		return render(view{
+			Model: result,
+			Rows:  result,
+			Columns: []column{
+				{Header: "New", Field: "new"},
+				{Header: "Updated", Field: "updated"},
+				{Header: "Removed", Field: "removed"},
+				{Header: "Transactions", Field: "transactions"},
+				dateColumn("Last Transaction", "lastVisibleTS"),
+			},
+		})
+
+	case exportOFX.FullCommand():
+		statement, err := exportStatement(ctx)
+		if err != nil {
+			return err
+		}
+		return writeOFX(os.Stdout, statement, time.Now())
+
+	case exportBeancount.FullCommand(), exportLedger.FullCommand():
+		statement, err := exportStatement(ctx)
+		if err != nil {
+			return err
+		}
+		var journal Journal
+		if cfg, err := readConfig(); err == nil {
+			journal = cfg.Journal
+		}
+		if command == exportBeancount.FullCommand() {
+			err = writeBeancount(os.Stdout, statement, journal)
+		} else {
+			err = writeLedger(os.Stdout, statement, journal)
+		}
+		return err
+
+	case exportCAMT053.FullCommand():
+		statement, err := exportStatement(ctx)
+		if err != nil {
+			return err
+		}
+		return writeCAMT053(os.Stdout, statement, time.Now())
+
+	case exportMT940.FullCommand():
+		statement, err := exportStatement(ctx)
+		if err != nil {
+			return err
+		}
+		return writeMT940(os.Stdout, statement)
+
+	case exportQIF.FullCommand(), exportCSV.FullCommand():
+		statement, err := exportStatement(ctx)
+		if err != nil {
+			return err
+		}
+		categories, err := categoryNames(ctx, client)
+		if err != nil {
+			return err
+		}
+		if command == exportQIF.FullCommand() {
+			err = writeQIF(os.Stdout, statement, categories)
+		} else {
+			err = writeCSVProfile(os.Stdout, statement, csvProfiles[*exportCSVProfile], categories)
+		}
+		return err
+
+	case balance.FullCommand():
+		balance, err := client.Balance(ctx)
+		if err != nil {
+			return err
+		}
+		return render(view{
+			Model: balance,
+			Rows:  balance,
+			Columns: []column{
+				amountColumn("Available Balance", "availableBalance", "currency", 2),
+				amountColumn("Usable Balance", "usableBalance", "currency", 2),
+			},
+		})
+
+	case contacts.FullCommand():
+		contacts, err := client.Contacts(ctx)
+		if err != nil {
+			return err
+		}
+		return render(view{
+			Model: contacts,
+			Rows:  contacts,
+			Columns: []column{
+				{Header: "Contact Name", Field: "name"},
+				{Header: "IBAN", Field: "account.iban"},
+				{Header: "BIC", Field: "account.bic"},
+				{Header: "Account Type", Field: "account.accountType"},
+			},
+		})
+
+	case limit.FullCommand():
+		limits, err := client.AccountLimit(ctx)
+		if err != nil {
+			return err
+		}
+		return render(view{
+			Model: limits,
+			Rows:  limits,
+			Columns: []column{
+				{Header: "Limit", Field: "limit"},
+				amountColumn("Amount", "amount", "currency", 2),
+			},
+		})
+
+	case info.FullCommand():
+		accountInfo, err := client.AccountInfo(ctx)
+		if err != nil {
+			return err
+		}
+		return render(view{
+			Model: accountInfo,
+			Rows:  accountInfo,
+			Columns: []column{
+				{Header: "ID", Field: "id"},
+				{Header: "First Name", Field: "firstName"},
+				{Header: "Last Name", Field: "lastName"},
+				{Header: "Email", Field: "email"},
+				{Header: "Mobile", Field: "mobilePhoneNumber"},
+				{Header: "Gender", Field: "gender"},
+				{Header: "Nationality", Field: "nationality"},
+			},
+		})
+
+	case savings.FullCommand():
+		savings, err := client.Savings(ctx)
+		if err != nil {
+			return err
+		}
+		return render(view{
+			Model: savings,
+			Rows:  &savings.Accounts,
+			Columns: []column{
+				{Header: "Account Name", Field: "name"},
+				decimalColumn("Balance", "balance", 1),
+				decimalColumn("Total Deposit", "totalDeposit", 1),
+				decimalColumn("Performance (%)", "performance", 100),
+				decimalColumn("Profit", "profit", 1),
+				decimalColumn("Monthly Amount", "monthlyAmount", 1),
+				{Header: "Option", Field: "optionId"},
+				{Header: "Status", Field: "status"},
+			},
+		})
+
+	case statements.FullCommand():
+		if len(*statementID) > 0 {
+			return saveStatement(ctx, client, *statementOutDir, *statementID, n26.StatementFormat(*statementFormat))
+		}
+		if !*statementAll && *statementYear == 0 && *statementFrom == "" && *statementTo == "" {
+			bankStatements, err := client.Statements(ctx)
+			if err != nil {
+				return err
+			}
+			return render(view{
+				Model:   bankStatements,
+				Rows:    bankStatements,
+				Columns: []column{{Header: "ID", Field: "id"}},
+			})
+		}
+		from, to, err := parseDateRange(*statementFrom, *statementTo, "", time.Now())
+		if err != nil {
+			return err
+		}
+		filter := statementFilter{Year: *statementYear, From: from, To: to}
+		downloads, err := downloadStatements(ctx, client, *statementOutDir, n26.StatementFormat(*statementFormat), filter, *statementParallel)
+		if downloads != nil {
+			// the table shows which of the statements failed
+			renderErr := render(view{
+				Model: downloads,
+				Rows:  downloads,
+				Columns: []column{
+					{Header: "ID", Field: "id"},
+					{Header: "File", Field: "file"},
+					{Header: "Status", Field: "status"},
+					{Header: "Error", Field: "error"},
+				},
+			})
+			if renderErr != nil {
+				return renderErr
+			}
+		}
+		return err
+
+	case stats.FullCommand():
+		from, to, err := parseDateRange(*statsFrom, *statsTo, "", time.Now())
+		if err != nil {
+			return err
+		}
+		stats, err := client.Stats(ctx, n26.StatsOptions{
+			Type:   *statsType,
+			From:   from,
+			To:     to,
+			Slices: *statsSlices,
+		})
+		if err != nil {
+			return err
+		}
+		var values []float64
+		var max float64
+		for _, item := range stats.Items {
+			values = append(values, item.Amount)
+			max = math.Max(max, math.Abs(item.Amount))
+		}
+		v := view{Model: stats, Rows: &stats.Items}
+		if stats.Type == n26.StatsCategory {
+			v.Columns = []column{
+				{Header: "Category", Field: "id", Format: func(row reflect.Value) string {
+					return strings.Replace(row.FieldByName("ID").String(), "micro-v2-", "", -1)
+				}},
+				decimalColumn("Amount", "amount", 1),
+				{Header: "", Field: "amount", Format: func(row reflect.Value) string {
+					return bar(row.FieldByName("Amount").Float(), max, 30)
+				}},
+			}
+		} else {
+			v.Columns = []column{
+				unixDateColumn("From", "from"),
+				unixDateColumn("To", "to"),
+				decimalColumn("Balance", "amount", 1),
+			}
+		}
+		err = render(v)
+		if err != nil {
+			return err
+		}
+		if stats.Type == n26.StatsAccount && *output == outputTable && *outputTemplate == "" {
+			fmt.Println(sparkline(values))
+		}
+
+	case status.FullCommand():
+		accountStatus, err := client.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return render(view{
+			Model:    accountStatus,
+			Rows:     accountStatus,
+			Columns:  fieldColumns(accountStatus),
+			Vertical: true,
+		})
+
+	case cards.FullCommand():
+		cards, err := client.Cards(ctx)
+		if err != nil {
+			return err
+		}
+		return render(view{
+			Model: cards,
+			Rows:  cards,
+			Columns: []column{
+				{Header: "ID", Field: "id"},
+				{Header: "Card Type", Field: "cardType"},
+				{Header: "Card Product Type", Field: "cardProductType"},
+				{Header: "Status", Field: "status"},
+				{Header: "Username on card", Field: "usernameOnCard"},
+			},
+		})
+
+	case blockCard.FullCommand():
+		card, err := client.BlockCard(ctx, *blockCardID)
+		if err != nil {
+			return err
+		}
+		return render(cardView(card))
+
+	case unblockCard.FullCommand():
+		card, err := client.UnblockCard(ctx, *unblockCardID)
+		if err != nil {
+			return err
+		}
+		return render(cardView(card))
+
+	case categories.FullCommand():
+		categories, err := client.Categories(ctx)
+		if err != nil {
+			return err
+		}
+		return render(view{
+			Model: categories,
+			Rows:  categories,
+			Columns: []column{
+				{Header: "ID", Field: "id"},
+				{Header: "Category Name", Field: "name"},
+			},
+		})
+
+	case spaces.FullCommand():
+		spaces, err := client.Spaces(ctx)
+		if err != nil {
+			return err
+		}
+		return render(view{
+			Model: spaces,
+			Rows:  &spaces.Spaces,
+			Columns: []column{
+				{Header: "Name", Field: "name"},
+				amountColumn("Available Balance", "balance.availableBalance", "balance.currency", 2),
+			},
+		})
+	}
+	return nil
+}
+
+func cardView(card *n26.N26CardV1) view {
+	return view{
+		Model: card,
+		Rows:  card,
+		Columns: []column{
+			{Header: "ID", Field: "id"},
+			{Header: "Card Type", Field: "cardType"},
+			{Header: "Status", Field: "Status"},
+		},
+	}
+}
+
+func exportStatement(ctx context.Context) (*statement, error) {
+	from, to, err := parseDateRange(*exportFrom, *exportTo, *exportSince, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return loadStatement(ctx, client, from, to, *exportPending)
+}
+
+func archivePath() string {
+	path, err := homedir.Expand(*archiveFile)
+	if err != nil {
+		return *archiveFile
+	}
+	return path
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/njuettner/n26"
+)
+
+const (
+	mt940InfoLines     = 6
+	mt940InfoLineWidth = 65
+)
+
+// writeMT940 writes the statement as SWIFT MT940 message, fields are
+// separated by CRLF as required by SWIFT
+func writeMT940(w io.Writer, s *statement) error {
+	currency := s.Account.Currency
+	account := s.Account.Iban
+	if s.Account.Bic != "" {
+		account = s.Account.Bic + "/" + account
+	}
+	lines := []string{
+		":20:N26" + s.To.Format("060102"),
+		":25:" + account,
+		":28C:00001/001",
+		":60F:" + mt940Balance(s.OpeningBalance, currency, s.From),
+	}
+	for _, transaction := range s.Transactions {
+		lines = append(lines, mt940Entry(transaction)...)
+	}
+	lines = append(lines, ":62F:"+mt940Balance(s.ClosingBalance, currency, s.To), "-")
+	_, err := io.WriteString(w, strings.Join(lines, "\r\n")+"\r\n")
+	return err
+}
+
+// mt940Entry returns the :61: statement line and the :86: information of a
+// transaction, the N26 ID is the bank reference
+func mt940Entry(transaction n26.N26Transaction) []string {
+	valueDate := millisToTime(transaction.VisibleTS)
+	entryDate := valueDate
+	if transaction.Confirmed > 0 {
+		entryDate = millisToTime(transaction.Confirmed)
+	}
+	line := fmt.Sprintf(":61:%s%s%s%s%sNONREF//%s",
+		valueDate.Format("060102"), entryDate.Format("0102"),
+		mt940Mark(transaction.Amount), mt940Amount(transaction.Amount),
+		mt940TransactionType(transaction),
+		truncate(strings.Replace(transaction.ID, "-", "", -1), 16))
+
+	var info []string
+	for _, text := range []string{transaction.PartnerName, transaction.ReferenceText} {
+		if text != "" {
+			info = append(info, swiftText(text))
+		}
+	}
+	if transaction.PartnerIban != "" {
+		info = append(info, swiftText(strings.TrimSpace("IBAN "+transaction.PartnerIban+" BIC "+transaction.PartnerBic)))
+	}
+	info = append(info, "N26 ID "+swiftText(transaction.ID))
+	return []string{line, ":86:" + strings.Join(wrap(strings.Join(info, " "), mt940InfoLineWidth, mt940InfoLines), "\r\n")}
+}
+
+// mt940TransactionType is N followed by the SWIFT transaction type code
+func mt940TransactionType(transaction n26.N26Transaction) string {
+	switch {
+	case transaction.MandateID != "":
+		return "NDDT"
+	case transaction.Type == "PT":
+		return "NMSC"
+	}
+	return "NTRF"
+}
+
+func mt940Balance(amount float64, currency string, date time.Time) string {
+	return mt940Mark(amount) + date.Format("060102") + currency + mt940Amount(amount)
+}
+
+func mt940Mark(amount float64) string {
+	if amount < 0 {
+		return "D"
+	}
+	return "C"
+}
+
+// mt940Amount uses a decimal comma and no sign
+func mt940Amount(amount float64) string {
+	return strings.Replace(strconv.FormatFloat(math.Abs(amount), 'f', 2, 64), ".", ",", 1)
+}
+
+var swiftReplacer = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "Ä", "Ae", "Ö", "Oe", "Ü", "Ue", "ß", "ss", "&", "+")
+
+// swiftText replaces characters outside of the SWIFT character set
+func swiftText(s string) string {
+	s = swiftReplacer.Replace(s)
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case strings.ContainsRune("/-?:().,'+ ", r):
+			return r
+		}
+		return ' '
+	}, s)
+}
+
+// wrap splits s into at most n lines of width characters
+func wrap(s string, width, n int) []string {
+	var lines []string
+	for len(s) > 0 && len(lines) < n {
+		if len(s) <= width {
+			lines = append(lines, s)
+			break
+		}
+		cut := strings.LastIndex(s[:width], " ")
+		if cut <= 0 {
+			cut = width
+		}
+		lines = append(lines, s[:cut])
+		s = strings.TrimLeft(s[cut:], " ")
+	}
+	return lines
+}
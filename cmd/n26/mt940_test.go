@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/njuettner/n26/n26test"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestWriteMT940(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := writeMT940(buf, testStatement())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "statement.mt940", buf.Bytes())
+	for i, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > 65 && !strings.HasPrefix(line, ":86:") || len(line) > 69 {
+			t.Errorf("line %d is too long: %q", i+1, line)
+		}
+	}
+}
+
+// an export without --from and without transactions starts at the balance
+func TestWriteMT940WithoutTransactions(t *testing.T) {
+	server := n26test.NewServer()
+	defer server.Close()
+	server.API.Transactions = nil
+	to := time.Date(2019, time.March, 31, 12, 0, 0, 0, time.UTC)
+	s, err := loadStatement(context.Background(), server.Client(), time.Time{}, to, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	err = writeMT940(buf, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "empty.mt940", buf.Bytes())
+
+	buf.Reset()
+	err = writeBeancount(buf, s, Journal{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "0001-01-01") || !strings.Contains(buf.String(), "2019-03-31 pad") {
+		t.Errorf("beancount journal does not start at the balance date:\n%s", buf)
+	}
+}
+
+// checkGolden compares got with the file name in testdata
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	golden := filepath.Join("testdata", name)
+	if *update {
+		err := ioutil.WriteFile(golden, got, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s, run go test -update if the change is intended\ngot:\n%s", golden, got)
+	}
+}
+
+func TestSwiftText(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"Bäckerei Müller & Söhne", "Baeckerei Mueller + Soehne"},
+		{"ÄÖÜ Straße", "AeOeUe Strasse"},
+		{"Gehalt 03/2019 (netto): 1.234,56", "Gehalt 03/2019 (netto): 1.234,56"},
+		{"50% off – #1 €", "50  off    1  "},
+	} {
+		got := swiftText(test.in)
+		if got != test.want {
+			t.Errorf("swiftText(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	got := wrap("aaa bbb ccc dddddddd eee", 8, 3)
+	want := []string{"aaa bbb", "ccc", "dddddddd"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("wrap = %q, want %q", got, want)
+	}
+	got = wrap("abcdefghij", 4, 6)
+	want = []string{"abcd", "efgh", "ij"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("wrap = %q, want %q", got, want)
+	}
+}
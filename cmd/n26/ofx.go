@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/njuettner/n26"
+)
+
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+// ofx is an OFX 2.2 bank statement response
+type ofx struct {
+	XMLName xml.Name `xml:"OFX"`
+	SignOn  struct {
+		Response struct {
+			Status   ofxStatus `xml:"STATUS"`
+			DTServer string    `xml:"DTSERVER"`
+			Language string    `xml:"LANGUAGE"`
+		} `xml:"SONRS"`
+	} `xml:"SIGNONMSGSRSV1"`
+	Bank struct {
+		Transaction struct {
+			TrnUID    string       `xml:"TRNUID"`
+			Status    ofxStatus    `xml:"STATUS"`
+			Statement ofxStatement `xml:"STMTRS"`
+		} `xml:"STMTTRNRS"`
+	} `xml:"BANKMSGSRSV1"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxStatement struct {
+	Currency        string         `xml:"CURDEF"`
+	Account         ofxBankAccount `xml:"BANKACCTFROM"`
+	TransactionList struct {
+		Start        string           `xml:"DTSTART"`
+		End          string           `xml:"DTEND"`
+		Transactions []ofxTransaction `xml:"STMTTRN"`
+	} `xml:"BANKTRANLIST"`
+	LedgerBalance    ofxBalance `xml:"LEDGERBAL"`
+	AvailableBalance ofxBalance `xml:"AVAILBAL"`
+}
+
+// ofxBankAccount identifies the account, BANKID has at most 9 and ACCTID at
+// most 22 characters
+type ofxBankAccount struct {
+	BankID      string `xml:"BANKID"`
+	BranchID    string `xml:"BRANCHID,omitempty"`
+	AccountID   string `xml:"ACCTID"`
+	AccountType string `xml:"ACCTTYPE"`
+	Key         string `xml:"ACCTKEY,omitempty"`
+}
+
+type ofxTransaction struct {
+	Type   string `xml:"TRNTYPE"`
+	Posted string `xml:"DTPOSTED"`
+	User   string `xml:"DTUSER,omitempty"`
+	Amount string `xml:"TRNAMT"`
+	FITID  string `xml:"FITID"`
+	Name   string `xml:"NAME,omitempty"`
+	Memo   string `xml:"MEMO,omitempty"`
+}
+
+type ofxBalance struct {
+	Amount string `xml:"BALAMT"`
+	AsOf   string `xml:"DTASOF"`
+}
+
+// writeOFX writes the statement as OFX 2.2 file, the transaction ID is the
+// FITID so finance software can skip transactions it already imported
+func writeOFX(w io.Writer, s *statement, now time.Time) error {
+	doc := ofx{}
+	doc.SignOn.Response.Status = ofxStatus{Code: 0, Severity: "INFO"}
+	doc.SignOn.Response.DTServer = ofxDate(now)
+	doc.SignOn.Response.Language = "ENG"
+	doc.Bank.Transaction.TrnUID = strconv.FormatInt(now.Unix(), 10)
+	doc.Bank.Transaction.Status = ofxStatus{Code: 0, Severity: "INFO"}
+
+	stmt := &doc.Bank.Transaction.Statement
+	stmt.Currency = s.Account.Currency
+	stmt.Account = ofxAccount(s.Account.Iban, s.Account.Bic)
+	stmt.Account.AccountType = "CHECKING"
+	stmt.TransactionList.Start = ofxDate(s.From)
+	stmt.TransactionList.End = ofxDate(s.To)
+	for _, transaction := range s.Transactions {
+		t := ofxTransaction{
+			Type:   ofxTransactionType(transaction),
+			Posted: ofxDate(millisToTime(transaction.VisibleTS)),
+			Amount: strconv.FormatFloat(transaction.Amount, 'f', 2, 64),
+			FITID:  transaction.ID,
+			Name:   truncate(transaction.PartnerName, 32),
+			Memo:   truncate(transaction.ReferenceText, 255),
+		}
+		if transaction.Confirmed > 0 {
+			t.Posted = ofxDate(millisToTime(transaction.Confirmed))
+			t.User = ofxDate(millisToTime(transaction.VisibleTS))
+		}
+		stmt.TransactionList.Transactions = append(stmt.TransactionList.Transactions, t)
+	}
+	stmt.LedgerBalance = ofxBalance{
+		Amount: strconv.FormatFloat(s.ClosingBalance, 'f', 2, 64),
+		AsOf:   ofxDate(s.To),
+	}
+	stmt.AvailableBalance = ofxBalance{
+		Amount: strconv.FormatFloat(s.Account.AvailableBalance, 'f', 2, 64),
+		AsOf:   ofxDate(now),
+	}
+
+	_, err := io.WriteString(w, ofxHeader)
+	if err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err = enc.Encode(doc)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// ofxAccount splits the IBAN into the national bank code, branch, account
+// number and check digits, an IBAN is too long for ACCTID in many countries
+func ofxAccount(iban, bic string) ofxBankAccount {
+	iban = strings.ToUpper(strings.Replace(iban, " ", "", -1))
+	bban := ""
+	if len(iban) > 4 {
+		bban = iban[4:]
+	}
+	switch {
+	case strings.HasPrefix(iban, "DE") && len(bban) == 18:
+		return ofxBankAccount{BankID: bban[:8], AccountID: bban[8:]}
+	case strings.HasPrefix(iban, "AT") && len(bban) == 16:
+		return ofxBankAccount{BankID: bban[:5], AccountID: bban[5:]}
+	case strings.HasPrefix(iban, "FR") && len(bban) == 23:
+		return ofxBankAccount{BankID: bban[:5], BranchID: bban[5:10], AccountID: bban[10:21], Key: bban[21:]}
+	case strings.HasPrefix(iban, "IT") && len(bban) == 23:
+		return ofxBankAccount{BankID: bban[1:6], BranchID: bban[6:11], AccountID: bban[11:], Key: bban[:1]}
+	case strings.HasPrefix(iban, "ES") && len(bban) == 20:
+		return ofxBankAccount{BankID: bban[:4], BranchID: bban[4:8], AccountID: bban[10:], Key: bban[8:10]}
+	}
+	// the BIC without branch code identifies the bank of other countries
+	account := ofxBankAccount{BankID: truncate(bic, 8), AccountID: iban}
+	if len(iban) > 22 {
+		account.AccountID = iban[len(iban)-22:]
+	}
+	return account
+}
+
+// ofxTransactionType maps the N26 transaction type, PT card payments,
+// DT outgoing and CT incoming transfers
+func ofxTransactionType(transaction n26.N26Transaction) string {
+	switch {
+	case transaction.Category == "micro-v2-atm":
+		return "ATM"
+	case transaction.Type == "PT":
+		return "POS"
+	case transaction.MandateID != "":
+		return "DIRECTDEBIT"
+	case transaction.Amount < 0:
+		return "DEBIT"
+	}
+	return "CREDIT"
+}
+
+func ofxDate(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
+
+// truncate cuts s to at most n runes
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOFXAccount(t *testing.T) {
+	for _, test := range []struct {
+		iban, bic string
+		want      ofxBankAccount
+	}{
+		{"DE89370400440532013000", "NTSBDEB1XXX", ofxBankAccount{BankID: "37040044", AccountID: "0532013000"}},
+		{"AT611904300234573201", "NTSBDEB1XXX", ofxBankAccount{BankID: "19043", AccountID: "00234573201"}},
+		{"FR76 3000 6000 0112 3456 7890 189", "NTSBFRM1XXX", ofxBankAccount{BankID: "30006", BranchID: "00001", AccountID: "12345678901", Key: "89"}},
+		{"IT60X0542811101000000123456", "NTSBITM1XXX", ofxBankAccount{BankID: "05428", BranchID: "11101", AccountID: "000000123456", Key: "X"}},
+		{"ES9121000418450200051332", "NTSBESM1XXX", ofxBankAccount{BankID: "2100", BranchID: "0418", AccountID: "0200051332", Key: "45"}},
+		{"MT84MALT011000012345MTLCAST001S", "MALTMTMTXXX", ofxBankAccount{BankID: "MALTMTMT", AccountID: "11000012345MTLCAST001S"}},
+	} {
+		got := ofxAccount(test.iban, test.bic)
+		if got != test.want {
+			t.Errorf("ofxAccount(%q) = %+v, want %+v", test.iban, got, test.want)
+		}
+	}
+}
+
+func TestWriteOFXAccountLimits(t *testing.T) {
+	s := testStatement()
+	s.Account.Iban = "FR7630006000011234567890189"
+	s.Account.Bic = "NTSBFRM1XXX"
+	buf := &bytes.Buffer{}
+	err := writeOFX(buf, s, time.Date(2019, time.April, 1, 8, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := ofx{}
+	err = xml.Unmarshal([]byte(strings.SplitN(buf.String(), "?>\n", 3)[2]), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := doc.Bank.Transaction.Statement.Account
+	if len(account.BankID) > 9 || len(account.BranchID) > 22 || len(account.AccountID) > 22 || len(account.Key) > 22 {
+		t.Errorf("account %+v exceeds the OFX limits", account)
+	}
+	want := ofxBankAccount{BankID: "30006", BranchID: "00001", AccountID: "12345678901", AccountType: "CHECKING", Key: "89"}
+	if account != want {
+		t.Errorf("got account %+v, want %+v", account, want)
+	}
+}
@@ -0,0 +1,494 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputCSV   = "csv"
+	outputTSV   = "tsv"
+)
+
+var outputFormats = []string{outputTable, outputJSON, outputYAML, outputCSV, outputTSV}
+
+// view describes how the result of a command is printed
+type view struct {
+	// Model is the full API response, used for json, yaml and templates
+	Model interface{}
+	// Rows is a struct or a slice of structs for table, csv and tsv
+	Rows interface{}
+	// Columns are the default table columns
+	Columns []column
+	// Vertical prints a single row as field and value pairs
+	Vertical bool
+}
+
+// column of a table, Field is the JSON path of the value in a row
+type column struct {
+	Header string
+	Field  string
+	// Format turns the row into the cell value, by default the field value is printed
+	Format func(row reflect.Value) string
+}
+
+// renderOptions are the global output flags
+type renderOptions struct {
+	Format   string
+	Columns  []string
+	SortBy   string
+	Template string
+}
+
+// render prints the result of a command as chosen by the output flags
+func render(v view) error {
+	var columns []string
+	if *outputColumns != "" {
+		columns = strings.Split(*outputColumns, ",")
+	}
+	return renderTo(os.Stdout, v, renderOptions{
+		Format:   *output,
+		Columns:  columns,
+		SortBy:   *outputSortBy,
+		Template: *outputTemplate,
+	})
+}
+
+func renderTo(w io.Writer, v view, opts renderOptions) error {
+	if opts.SortBy != "" {
+		err := sortRows(v.Rows, opts.SortBy)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.Template != "" {
+		return writeTemplate(w, opts.Template, v.Model)
+	}
+	switch opts.Format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v.Model)
+	case outputYAML:
+		return writeYAML(w, v.Model)
+	case outputCSV, outputTSV:
+		if v.Rows == nil {
+			return fmt.Errorf("%s output is not supported for this command", opts.Format)
+		}
+		columns, err := selectColumns(v, opts.Columns, false)
+		if err != nil {
+			return err
+		}
+		separator := ','
+		if opts.Format == outputTSV {
+			separator = '\t'
+		}
+		return writeCSV(w, separator, rowValues(v.Rows), columns)
+	default:
+		if v.Rows == nil {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(v.Model)
+		}
+		columns, err := selectColumns(v, opts.Columns, true)
+		if err != nil {
+			return err
+		}
+		writeTable(w, rowValues(v.Rows), columns, v.Vertical)
+		return nil
+	}
+}
+
+// selectColumns returns the columns picked with --columns, otherwise the
+// default columns for tables and every field for csv and tsv
+func selectColumns(v view, names []string, table bool) ([]column, error) {
+	rowType := reflect.TypeOf(v.Rows)
+	for rowType.Kind() == reflect.Ptr || rowType.Kind() == reflect.Slice {
+		rowType = rowType.Elem()
+	}
+	if len(names) == 0 {
+		if table && len(v.Columns) > 0 {
+			return v.Columns, nil
+		}
+		names = fieldNames("", rowType)
+	}
+	columns := []column{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !hasField(rowType, name) {
+			return nil, fmt.Errorf("unknown column %q, available: %s",
+				name, strings.Join(fieldNames("", rowType), ", "))
+		}
+		col := column{Header: name, Field: name}
+		if table {
+			for _, defaultColumn := range v.Columns {
+				if strings.EqualFold(defaultColumn.Field, name) {
+					col = defaultColumn
+				}
+			}
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+func (c column) value(row reflect.Value) string {
+	if c.Format != nil {
+		return c.Format(row)
+	}
+	field, _ := lookupField(row, c.Field)
+	return formatValue(field)
+}
+
+func writeTable(w io.Writer, rows []reflect.Value, columns []column, vertical bool) {
+	table := tablewriter.NewWriter(w)
+	table.SetBorder(false)
+	if vertical && len(rows) == 1 {
+		table.SetHeader([]string{"Field", "Value"})
+		for _, col := range columns {
+			table.Append([]string{col.Header, col.value(rows[0])})
+		}
+		table.Render()
+		return
+	}
+	headers := []string{}
+	for _, col := range columns {
+		headers = append(headers, col.Header)
+	}
+	table.SetHeader(headers)
+	for _, row := range rows {
+		data := []string{}
+		for _, col := range columns {
+			data = append(data, col.value(row))
+		}
+		table.Append(data)
+	}
+	table.Render()
+}
+
+func writeCSV(w io.Writer, separator rune, rows []reflect.Value, columns []column) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = separator
+	headers := []string{}
+	for _, col := range columns {
+		headers = append(headers, col.Header)
+	}
+	err := writer.Write(headers)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := []string{}
+		for _, col := range columns {
+			record = append(record, col.value(row))
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeYAML goes through JSON so the keys match the API and the json output
+func writeYAML(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if len(data) > 0 && data[0] == '[' {
+		list := []yaml.MapSlice{}
+		err = yaml.Unmarshal(data, &list)
+		doc = list
+	} else {
+		m := yaml.MapSlice{}
+		err = yaml.Unmarshal(data, &m)
+		doc = m
+	}
+	if err != nil {
+		return err
+	}
+	data, err = yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+var templateFuncs = template.FuncMap{
+	"date": formatDate,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+func writeTemplate(w io.Writer, text string, model interface{}) error {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, model)
+}
+
+// sortRows sorts a slice of rows in place by a field, a leading - sorts descending
+func sortRows(rows interface{}, field string) error {
+	v := reflect.Indirect(reflect.ValueOf(rows))
+	if v.Kind() != reflect.Slice {
+		return nil
+	}
+	descending := strings.HasPrefix(field, "-")
+	field = strings.TrimPrefix(field, "-")
+	if !hasField(v.Type().Elem(), field) {
+		return fmt.Errorf("unknown field %q to sort by", field)
+	}
+	swap := reflect.Swapper(v.Interface())
+	keys := make([]reflect.Value, v.Len())
+	for i := range keys {
+		// copy the key, the field itself moves with the swapped rows
+		key, _ := lookupField(v.Index(i), field)
+		keys[i] = reflect.ValueOf(key.Interface())
+	}
+	sort.Stable(&rowSorter{keys: keys, swap: swap, descending: descending})
+	return nil
+}
+
+type rowSorter struct {
+	keys       []reflect.Value
+	swap       func(i, j int)
+	descending bool
+}
+
+func (s *rowSorter) Len() int { return len(s.keys) }
+
+func (s *rowSorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.swap(i, j)
+}
+
+func (s *rowSorter) Less(i, j int) bool {
+	if s.descending {
+		i, j = j, i
+	}
+	a, b := s.keys[i], s.keys[j]
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	}
+	return formatValue(a) < formatValue(b)
+}
+
+func rowValues(rows interface{}) []reflect.Value {
+	v := reflect.Indirect(reflect.ValueOf(rows))
+	if v.Kind() != reflect.Slice {
+		return []reflect.Value{v}
+	}
+	values := []reflect.Value{}
+	for i := 0; i < v.Len(); i++ {
+		values = append(values, reflect.Indirect(v.Index(i)))
+	}
+	return values
+}
+
+// lookupField follows a dotted path of JSON or Go field names, case insensitive
+func lookupField(v reflect.Value, path string) (reflect.Value, bool) {
+	for _, name := range strings.Split(path, ".") {
+		v = reflect.Indirect(v)
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		index, ok := fieldIndex(v.Type(), name)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		v = v.Field(index)
+	}
+	return v, true
+}
+
+func hasField(t reflect.Type, path string) bool {
+	for _, name := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		index, ok := fieldIndex(t, name)
+		if !ok {
+			return false
+		}
+		t = t.Field(index).Type
+	}
+	return true
+}
+
+func fieldIndex(t reflect.Type, name string) (int, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if strings.EqualFold(jsonName(field), name) || strings.EqualFold(field.Name, name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// fieldNames returns the JSON names of all struct fields, nested structs
+// are flattened with a dot
+func fieldNames(prefix string, t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return []string{strings.TrimSuffix(prefix, ".")}
+	}
+	names := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := jsonName(field)
+		if field.Type.Kind() == reflect.Struct {
+			names = append(names, fieldNames(prefix+name+".", field.Type)...)
+			continue
+		}
+		names = append(names, prefix+name)
+	}
+	return names
+}
+
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return ""
+		}
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+	return string(data)
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// fieldColumns returns a column for every field of a row type
+func fieldColumns(rows interface{}) []column {
+	rowType := reflect.TypeOf(rows)
+	for rowType.Kind() == reflect.Ptr || rowType.Kind() == reflect.Slice {
+		rowType = rowType.Elem()
+	}
+	columns := []column{}
+	for _, name := range fieldNames("", rowType) {
+		columns = append(columns, column{Header: name, Field: name})
+	}
+	return columns
+}
+
+// amountColumn prints an amount together with its currency
+func amountColumn(header, amountField, currencyField string, decimals int) column {
+	return column{
+		Header: header,
+		Field:  amountField,
+		Format: func(row reflect.Value) string {
+			amount, _ := lookupField(row, amountField)
+			currency, _ := lookupField(row, currencyField)
+			return strings.TrimSpace(fmt.Sprintf("%s %s",
+				strconv.FormatFloat(amount.Float(), 'f', decimals, 64),
+				formatValue(currency)))
+		},
+	}
+}
+
+// decimalColumn prints a number with two decimals, scaled by factor
+func decimalColumn(header, field string, factor float64) column {
+	return column{
+		Header: header,
+		Field:  field,
+		Format: func(row reflect.Value) string {
+			value, _ := lookupField(row, field)
+			return fmt.Sprintf("%.2f", value.Float()*factor)
+		},
+	}
+}
+
+// dateColumn prints a timestamp in milliseconds as a date
+func dateColumn(header, field string) column {
+	return column{
+		Header: header,
+		Field:  field,
+		Format: func(row reflect.Value) string {
+			value, _ := lookupField(row, field)
+			if value.Int() == 0 {
+				return ""
+			}
+			return formatDate(value.Int())
+		},
+	}
+}
+
+// unixDateColumn prints a timestamp in seconds as a date
+func unixDateColumn(header, field string) column {
+	return column{
+		Header: header,
+		Field:  field,
+		Format: func(row reflect.Value) string {
+			value, _ := lookupField(row, field)
+			if value.Int() == 0 {
+				return ""
+			}
+			return formatDate(value.Int() * 1000)
+		},
+	}
+}
+
+// formatDate formats a timestamp in milliseconds as used by the N26 API
+func formatDate(ms int64) string {
+	return millisToTime(ms).Format("2006-01-02")
+}
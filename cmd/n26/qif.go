@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const qifDateFormat = "01/02/2006"
+
+// writeQIF writes the statement as QIF bank account, categories are the
+// names of the N26 categories
+func writeQIF(w io.Writer, s *statement, categories map[string]string) error {
+	_, err := io.WriteString(w, "!Type:Bank\n")
+	if err != nil {
+		return err
+	}
+	for _, transaction := range s.Transactions {
+		lines := []string{
+			"D" + millisToTime(transaction.VisibleTS).Format(qifDateFormat),
+			"T" + formatAmount(transaction.Amount),
+		}
+		if !transaction.Pending {
+			lines = append(lines, "C*")
+		}
+		if transaction.PartnerName != "" {
+			lines = append(lines, "P"+qifText(transaction.PartnerName))
+		}
+		if transaction.ReferenceText != "" {
+			lines = append(lines, "M"+qifText(transaction.ReferenceText))
+		}
+		if category := categories[transaction.Category]; category != "" {
+			lines = append(lines, "L"+qifText(category))
+		}
+		_, err = fmt.Fprintf(w, "%s\n^\n", strings.Join(lines, "\n"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// qifText keeps a value on its line
+func qifText(s string) string {
+	return strings.NewReplacer("\n", " ", "\r", " ").Replace(s)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// passwordEnv overrides every other password source
+	passwordEnv = "N26_PASSWORD"
+	// keyringService and keyringTokenService name the keyring entries of
+	// the password and the OAuth token, the account is the N26 email
+	keyringService      = "n26"
+	keyringTokenService = "n26-token"
+)
+
+// lookupPassword returns the password from N26_PASSWORD, password_command,
+// the config file or the keyring, in that order
+func (c *Credentials) lookupPassword() (string, error) {
+	if password := os.Getenv(passwordEnv); password != "" {
+		return password, nil
+	}
+	if c.PasswordCommand != "" {
+		return runPasswordCommand(c.PasswordCommand)
+	}
+	if c.Password != "" {
+		return c.Password, nil
+	}
+	if c.Keyring {
+		password, err := keyring.Get(keyringService, c.Username)
+		if err != nil {
+			return "", fmt.Errorf("could not read password of %s from keyring: %s", c.Username, err)
+		}
+		return password, nil
+	}
+	return "", fmt.Errorf("no password configured, run n26 init or set %s", passwordEnv)
+}
+
+// runPasswordCommand runs command in the shell and returns the first line of
+// its output like pass does, the command can prompt on the terminal
+func runPasswordCommand(command string) (string, error) {
+	cmd := exec.Command("sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("password_command %q failed: %s", command, err)
+	}
+	password := strings.TrimRight(strings.SplitN(string(out), "\n", 2)[0], "\r")
+	if password == "" {
+		return "", fmt.Errorf("password_command %q returned no password", command)
+	}
+	return password, nil
+}
+
+// keyringTokenStore keeps the OAuth token as JSON in the keyring
+type keyringTokenStore struct {
+	user string
+}
+
+func (s *keyringTokenStore) Token() (*oauth2.Token, error) {
+	data, err := keyring.Get(keyringTokenService, s.user)
+	if err == keyring.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read token from keyring: %s", err)
+	}
+	token := &oauth2.Token{}
+	err = json.Unmarshal([]byte(data), token)
+	if err != nil {
+		return nil, fmt.Errorf("could not read token from keyring: %s", err)
+	}
+	return token, nil
+}
+
+func (s *keyringTokenStore) SetToken(token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return keyring.Set(keyringTokenService, s.user, string(data))
+}
+
+// deleteToken removes a token stored by an earlier login
+func (s *keyringTokenStore) deleteToken() error {
+	err := keyring.Delete(keyringTokenService, s.user)
+	if err != nil && err != keyring.ErrNotFound {
+		return fmt.Errorf("could not remove token from keyring: %s", err)
+	}
+	return nil
+}
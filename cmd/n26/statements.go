@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/njuettner/n26"
+)
+
+// checksumFile records the SHA-256 of every downloaded statement in the
+// format of sha256sum, a file is only skipped when it still matches
+const checksumFile = "SHA256SUMS"
+
+// statementDownload is the result of downloading a single statement
+type statementDownload struct {
+	ID     string `json:"id"`
+	File   string `json:"file"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	sum string
+}
+
+// statementFilter selects statements by year or by the months within from and to
+type statementFilter struct {
+	Year int
+	From time.Time
+	To   time.Time
+}
+
+func (f statementFilter) match(year, month int) bool {
+	if f.Year != 0 && year != f.Year {
+		return false
+	}
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0)
+	if !f.From.IsZero() && !end.After(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && start.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// downloadStatements saves the selected statements into dir with at most
+// concurrency downloads at a time, failed downloads are reported in the
+// result and the returned error
+func downloadStatements(ctx context.Context, client n26.N26Interface, dir string, format n26.StatementFormat, filter statementFilter, concurrency int) ([]statementDownload, error) {
+	statements, err := client.Statements(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	checksums, err := readChecksums(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var downloads []statementDownload
+	for _, statement := range *statements {
+		if filter.match(statement.Year, statement.Month) {
+			downloads = append(downloads, statementDownload{ID: statement.ID, File: statement.ID + "." + string(format)})
+		}
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := range downloads {
+		d := &downloads[i]
+		path := filepath.Join(dir, d.File)
+		if sum, ok := checksums[d.File]; ok && fileChecksum(path) == sum {
+			d.Status = "skipped"
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			sum, err := downloadStatement(ctx, client, d.ID, format, path)
+			if err != nil {
+				d.Status = "failed"
+				d.Error = err.Error()
+				return
+			}
+			d.Status = "downloaded"
+			d.sum = sum
+		}()
+	}
+	wg.Wait()
+	for _, d := range downloads {
+		if d.Status == "downloaded" {
+			checksums[d.File] = d.sum
+		}
+	}
+
+	err = writeChecksums(dir, checksums)
+	if err != nil {
+		return downloads, err
+	}
+	var failed []string
+	for _, d := range downloads {
+		if d.Status == "failed" {
+			failed = append(failed, d.ID)
+		}
+	}
+	if len(failed) > 0 {
+		return downloads, fmt.Errorf("could not download %s", strings.Join(failed, ", "))
+	}
+	return downloads, nil
+}
+
+// saveStatement downloads a single statement into dir and records its
+// checksum, so bulk downloads skip it later
+func saveStatement(ctx context.Context, client n26.N26Interface, dir, id string, format n26.StatementFormat) error {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+	checksums, err := readChecksums(dir)
+	if err != nil {
+		return err
+	}
+	file := id + "." + string(format)
+	sum, err := downloadStatement(ctx, client, id, format, filepath.Join(dir, file))
+	if err != nil {
+		return err
+	}
+	checksums[file] = sum
+	return writeChecksums(dir, checksums)
+}
+
+// downloadStatement saves a statement to path and returns its checksum, the
+// file is written to a temporary file first so it is never left incomplete
+func downloadStatement(ctx context.Context, client n26.N26Interface, id string, format n26.StatementFormat, path string) (string, error) {
+	h := sha256.New()
+	err := writeFileAtomic(path, 0600, func(w io.Writer) error {
+		head := &headWriter{}
+		err := client.Statement(ctx, id, format, io.MultiWriter(w, h, head))
+		if err != nil {
+			return err
+		}
+		return head.check(id, format)
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// headWriter keeps the first bytes written to check the file format
+type headWriter struct {
+	head []byte
+}
+
+// sniffLen is the number of bytes http.DetectContentType looks at
+const sniffLen = 512
+
+func (c *headWriter) Write(p []byte) (int, error) {
+	if n := sniffLen - len(c.head); n > 0 {
+		if n > len(p) {
+			n = len(p)
+		}
+		c.head = append(c.head, p[:n]...)
+	}
+	return len(p), nil
+}
+
+// check returns an error unless the statement looks like format, a PDF must
+// start with its header and a CSV file must be text
+func (c *headWriter) check(id string, format n26.StatementFormat) error {
+	if format == n26.StatementPDF {
+		if !bytes.HasPrefix(c.head, []byte("%PDF-")) {
+			return fmt.Errorf("%s is not a PDF", id)
+		}
+		return nil
+	}
+	if len(c.head) == 0 || !strings.HasPrefix(http.DetectContentType(c.head), "text/") {
+		return fmt.Errorf("%s is not a CSV file", id)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces path with what write writes, on failure path is
+// left untouched
+func writeFileAtomic(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	bw := bufio.NewWriter(tmp)
+	err = write(bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func fileChecksum(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	h := sha256.New()
+	_, err = bufio.NewReader(f).WriteTo(h)
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func readChecksums(dir string) (map[string]string, error) {
+	checksums := map[string]string{}
+	data, err := ioutil.ReadFile(filepath.Join(dir, checksumFile))
+	if os.IsNotExist(err) {
+		return checksums, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			checksums[fields[1]] = fields[0]
+		}
+	}
+	return checksums, nil
+}
+
+func writeChecksums(dir string, checksums map[string]string) error {
+	files := make([]string, 0, len(checksums))
+	for file := range checksums {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	var buf bytes.Buffer
+	for _, file := range files {
+		fmt.Fprintf(&buf, "%s  %s\n", checksums[file], file)
+	}
+	return writeFileAtomic(filepath.Join(dir, checksumFile), 0600, func(w io.Writer) error {
+		_, err := buf.WriteTo(w)
+		return err
+	})
+}
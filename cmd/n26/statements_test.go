@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/njuettner/n26"
+	"github.com/njuettner/n26/n26test"
+)
+
+func TestDownloadStatementsAll(t *testing.T) {
+	server := n26test.NewServer()
+	defer server.Close()
+	var statements []string
+	for year := 1990; year < 2015; year++ {
+		for month := 1; month <= 12; month++ {
+			id := fmt.Sprintf("statement-%d-%02d", year, month)
+			statements = append(statements, fmt.Sprintf(`{"id": %q, "url": "/api/statements/%s", "month": %d, "year": %d}`, id, id, month, year))
+		}
+	}
+	server.API.Statements = nil
+	err := json.Unmarshal([]byte("["+strings.Join(statements, ",")+"]"), &server.API.Statements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "n26")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+	client := server.Client()
+
+	_, err = downloadStatements(ctx, client, dir, n26.StatementPDF, statementFilter{Year: 2000}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a changed file is downloaded again
+	err = ioutil.WriteFile(filepath.Join(dir, "statement-2000-06.pdf"), []byte("changed"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	downloads, err := downloadStatements(ctx, client, dir, n26.StatementPDF, statementFilter{}, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := map[string]int{}
+	for _, d := range downloads {
+		status[d.Status]++
+	}
+	if status["skipped"] != 11 || status["downloaded"] != len(statements)-11 {
+		t.Errorf("got %v, want 11 skipped and %d downloaded", status, len(statements)-11)
+	}
+	checksums, err := readChecksums(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checksums) != len(statements) {
+		t.Errorf("%s has %d entries, want %d", checksumFile, len(checksums), len(statements))
+	}
+	for file, sum := range checksums {
+		if fileChecksum(filepath.Join(dir, file)) != sum {
+			t.Errorf("checksum of %s does not match", file)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"math"
+	"strings"
+)
+
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws a series of values as a line of block characters
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := len(sparkTicks) - 1
+		if max > min {
+			i = int((v - min) / (max - min) * float64(len(sparkTicks)-1))
+		}
+		b.WriteRune(sparkTicks[i])
+	}
+	return b.String()
+}
+
+// bar draws value as share of max with at most width characters
+func bar(value, max float64, width int) string {
+	if max == 0 {
+		return ""
+	}
+	n := int(math.Round(math.Abs(value) / max * float64(width)))
+	return strings.Repeat("█", n)
+}
@@ -0,0 +1,95 @@
+package n26
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// APIError is returned when the N26 API answers with an error status
+type APIError struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+	// Code is the error field of the response, e.g. invalid_token
+	Code string
+	// Description is the error_description field of the response
+	Description string
+	// Method and Endpoint of the failed request
+	Method   string
+	Endpoint string
+	// Body is the raw response body, also set if it was not JSON
+	Body []byte
+	// MFAToken is set when the login needs a second factor
+	MFAToken string
+}
+
+func (e *APIError) Error() string {
+	msg := e.Description
+	if msg == "" {
+		msg = e.Code
+	}
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Endpoint, e.StatusCode, msg)
+}
+
+// IsUnauthorized reports whether the credentials or the token were rejected
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusUnauthorized || apiErr.Code == "invalid_grant"
+}
+
+// IsRateLimited reports whether N26 rejected the request because of too many requests
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsNotFound reports whether the requested resource does not exist
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsMFARequired reports whether the login needs a second factor
+func IsMFARequired(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == "mfa_required"
+}
+
+func hasStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// checkHTTPStatus turns error responses into an APIError
+func checkHTTPStatus(resp *http.Response) error {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.Endpoint = resp.Request.URL.Path
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		apiErr.Description = fmt.Sprintf("could not read response: %s", err)
+		return apiErr
+	}
+	apiErr.Body = body
+	n26Error := &struct {
+		N26Error
+		MFAToken string `json:"mfaToken"`
+	}{}
+	if json.Unmarshal(body, n26Error) == nil {
+		apiErr.Code = n26Error.Error
+		apiErr.Description = n26Error.ErrorDescription
+		apiErr.MFAToken = n26Error.MFAToken
+	}
+	return apiErr
+}
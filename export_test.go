@@ -0,0 +1,13 @@
+package n26
+
+import "time"
+
+// SetMFAPollInterval shortens the polling for the app approval in tests and
+// returns a function restoring the interval
+func SetMFAPollInterval(interval time.Duration) func() {
+	previous := mfaPollInterval
+	mfaPollInterval = interval
+	return func() {
+		mfaPollInterval = previous
+	}
+}
@@ -0,0 +1,87 @@
+package n26
+
+import "context"
+
+// DefaultPageSize is the number of transactions fetched per request by
+// TransactionsIter unless a Limit is given
+const DefaultPageSize = 100
+
+// TransactionsIterator pages through transactions, newest first
+//
+//	it := client.TransactionsIter(ctx, n26.TransactionsOptions{})
+//	for it.Next() {
+//		transaction := it.Transaction()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type TransactionsIterator struct {
+	ctx     context.Context
+	client  N26Interface
+	opts    TransactionsOptions
+	page    N26Transactions
+	current N26Transaction
+	done    bool
+	err     error
+}
+
+// TransactionsIter returns an iterator over all transactions matching opts,
+// opts.Limit is the page size
+func (c *Client) TransactionsIter(ctx context.Context, opts TransactionsOptions) *TransactionsIterator {
+	return NewTransactionsIterator(ctx, c, opts)
+}
+
+// NewTransactionsIterator pages through the transactions of any N26Interface
+func NewTransactionsIterator(ctx context.Context, client N26Interface, opts TransactionsOptions) *TransactionsIterator {
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultPageSize
+	}
+	return &TransactionsIterator{ctx: ctx, client: client, opts: opts}
+}
+
+// Next advances to the next transaction and fetches a new page if needed,
+// it returns false when all transactions were read or an error occurred
+func (it *TransactionsIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+		transactions, err := it.client.Transactions(it.ctx, it.opts)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = *transactions
+		if len(it.page) < it.opts.Limit {
+			it.done = true
+		}
+		if len(it.page) == 0 {
+			return false
+		}
+		it.opts.LastID = it.page[len(it.page)-1].ID
+	}
+	it.current = it.page[0]
+	it.page = it.page[1:]
+	return true
+}
+
+// Transaction returns the current transaction
+func (it *TransactionsIterator) Transaction() N26Transaction {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration
+func (it *TransactionsIterator) Err() error {
+	return it.err
+}
+
+// All reads the remaining transactions
+func (it *TransactionsIterator) All() (*N26Transactions, error) {
+	transactions := N26Transactions{}
+	for it.Next() {
+		transactions = append(transactions, it.Transaction())
+	}
+	return &transactions, it.Err()
+}
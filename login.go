@@ -0,0 +1,190 @@
+package n26
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// MFATypeApp waits until the login is approved in the paired N26 app
+	MFATypeApp = "oob"
+	// MFATypeSMS asks for the one-time password sent via SMS
+	MFATypeSMS = "otp"
+
+	mfaTimeout = 5 * time.Minute
+
+	// errorAuthorizationPending is returned while the login waits for the
+	// approval in the app
+	errorAuthorizationPending = "authorization_pending"
+)
+
+var mfaPollInterval = 2 * time.Second
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// Login authenticates with email and password, completes the two-factor
+// challenge if N26 asks for it and stores the resulting token
+func (c *Client) Login(ctx context.Context) error {
+	token, err := c.login(ctx)
+	if err != nil {
+		return err
+	}
+	if c.TokenStore != nil {
+		return c.TokenStore.SetToken(token)
+	}
+	return nil
+}
+
+func (c *Client) login(ctx context.Context) (*oauth2.Token, error) {
+	password := c.Password
+	if password == "" && c.PasswordFunc != nil {
+		var err error
+		password, err = c.PasswordFunc()
+		if err != nil {
+			return nil, err
+		}
+	}
+	v := url.Values{}
+	v.Set("grant_type", "password")
+	v.Set("username", c.Email)
+	v.Set("password", password)
+	token, err := c.requestToken(ctx, v)
+	if apiErr, ok := err.(*APIError); ok && apiErr.Code == "mfa_required" {
+		return c.loginMFA(ctx, apiErr.MFAToken)
+	}
+	return token, err
+}
+
+func (c *Client) loginMFA(ctx context.Context, mfaToken string) (*oauth2.Token, error) {
+	challengeType := c.MFAType
+	if challengeType == "" {
+		challengeType = MFATypeApp
+	}
+	err := c.requestChallenge(ctx, challengeType, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	var otp string
+	if c.MFAPrompt != nil {
+		otp, err = c.MFAPrompt(challengeType)
+		if err != nil {
+			return nil, err
+		}
+	}
+	v := url.Values{}
+	v.Set("mfaToken", mfaToken)
+	if challengeType == MFATypeSMS {
+		if otp == "" {
+			return nil, fmt.Errorf("N26 sent a one-time password but none was entered")
+		}
+		v.Set("grant_type", "mfa_otp")
+		v.Set("otp", strings.TrimSpace(otp))
+		return c.requestToken(ctx, v)
+	}
+
+	v.Set("grant_type", "mfa_oob")
+	ctx, cancel := context.WithTimeout(ctx, mfaTimeout)
+	defer cancel()
+	ticker := time.NewTicker(mfaPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("login was not approved in the N26 app: %s", ctx.Err())
+		case <-ticker.C:
+		}
+		token, err := c.requestToken(ctx, v)
+		if apiErr, ok := err.(*APIError); ok && apiErr.Code == errorAuthorizationPending {
+			continue
+		}
+		return token, err
+	}
+}
+
+func (c *Client) requestChallenge(ctx context.Context, challengeType, mfaToken string) error {
+	body, err := json.Marshal(map[string]string{
+		"challengeType": challengeType,
+		"mfaToken":      mfaToken,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", c.apiURL("/api/mfa/challenge"), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setAuthHeaders(req)
+	resp, err := c.baseHTTPClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkHTTPStatus(resp)
+}
+
+func (c *Client) requestToken(ctx context.Context, v url.Values) (*oauth2.Token, error) {
+	req, err := http.NewRequest("POST", c.apiURL("/oauth/token"), strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.setAuthHeaders(req)
+	resp, err := c.baseHTTPClient().Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	err = checkHTTPStatus(resp)
+	if err != nil {
+		return nil, err
+	}
+	tr := tokenResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&tr)
+	if err != nil {
+		return nil, err
+	}
+	token := &oauth2.Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+	}
+	if tr.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+func (c *Client) setAuthHeaders(req *http.Request) {
+	c.setUserAgent(req)
+	req.SetBasicAuth("android", "secret")
+	if c.DeviceToken != "" {
+		req.Header.Set("device-token", c.DeviceToken)
+	}
+}
+
+// NewDeviceToken returns a random UUID identifying this installation
+func NewDeviceToken() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,220 @@
+package n26test
+
+// Seeded fixture data, shaped like the responses of the real API
+
+const accountJSON = `{
+	"availableBalance": 1523.57,
+	"usableBalance": 1523.57,
+	"currency": "EUR",
+	"bankBalance": 1523.57,
+	"iban": "DE89370400440532013000",
+	"bic": "NTSBDEB1XXX",
+	"bankName": "N26 Bank",
+	"seized": false,
+	"id": "a1b2c3d4-0000-4000-8000-000000000001"
+}`
+
+const transactionsJSON = `[
+	{
+		"id": "t-0012", "userId": "u-0001", "type": "PT", "amount": -42.9, "currencyCode": "EUR",
+		"visibleTS": 1553083200000, "partnerName": "REWE Markt", "accountId": "a1b2c3d4-0000-4000-8000-000000000001",
+		"category": "micro-v2-food-groceries", "referenceText": "", "pending": true,
+		"transactionNature": "NORMAL", "createdTS": 1553083200000, "confirmed": 0
+	},
+	{
+		"id": "t-0011", "userId": "u-0001", "type": "DT", "amount": -9.99, "currencyCode": "EUR",
+		"visibleTS": 1552651200000, "partnerName": "Spotify AB", "partnerIban": "SE3550000000054910000003",
+		"partnerBic": "ESSESESS", "accountId": "a1b2c3d4-0000-4000-8000-000000000001",
+		"category": "micro-v2-media-electronics", "referenceText": "Spotify Premium", "recurring": true,
+		"transactionNature": "NORMAL", "createdTS": 1552651200000, "confirmed": 1552651200000,
+		"mandateId": "M-77821", "creditorIdentifier": "SE00ZZZ5567037485", "creditorName": "Spotify AB"
+	},
+	{
+		"id": "t-0010", "userId": "u-0001", "type": "PT", "amount": -23.5, "currencyCode": "EUR",
+		"visibleTS": 1552219200000, "partnerName": "Deutsche Bahn", "accountId": "a1b2c3d4-0000-4000-8000-000000000001",
+		"category": "micro-v2-travel-holidays", "referenceText": "", "transactionNature": "NORMAL",
+		"createdTS": 1552219200000, "confirmed": 1552305600000
+	},
+	{
+		"id": "t-0009", "userId": "u-0001", "type": "CT", "amount": 2850, "currencyCode": "EUR",
+		"visibleTS": 1551441600000, "partnerName": "ACME GmbH", "partnerIban": "DE02120300000000202051",
+		"partnerBic": "BYLADEM1001", "accountId": "a1b2c3d4-0000-4000-8000-000000000001",
+		"category": "micro-v2-income", "referenceText": "Gehalt Maerz 2019", "transactionNature": "NORMAL",
+		"createdTS": 1551441600000, "confirmed": 1551441600000
+	},
+	{
+		"id": "t-0008", "userId": "u-0001", "type": "DT", "amount": -950, "currencyCode": "EUR",
+		"visibleTS": 1551355200000, "partnerName": "Hausverwaltung Schmidt", "partnerIban": "DE02100500000054540402",
+		"partnerBic": "BELADEBEXXX", "accountId": "a1b2c3d4-0000-4000-8000-000000000001",
+		"category": "micro-v2-household-utilities", "referenceText": "Miete Maerz", "recurring": true,
+		"transactionNature": "NORMAL", "createdTS": 1551355200000, "confirmed": 1551355200000
+	},
+	{
+		"id": "t-0007", "userId": "u-0001", "type": "PT", "amount": -64.2, "currencyCode": "EUR",
+		"visibleTS": 1550145600000, "partnerName": "Restaurant Da Mario", "accountId": "a1b2c3d4-0000-4000-8000-000000000001",
+		"category": "micro-v2-food-groceries", "referenceText": "", "transactionNature": "NORMAL",
+		"createdTS": 1550145600000, "confirmed": 1550232000000
+	},
+	{
+		"id": "t-0006", "userId": "u-0001", "type": "CT", "amount": 25, "currencyCode": "EUR",
+		"visibleTS": 1549800000000, "partnerName": "Max Mustermann", "partnerIban": "DE12500105170648489890",
+		"partnerBic": "INGDDEFFXXX", "accountId": "a1b2c3d4-0000-4000-8000-000000000001",
+		"category": "micro-v2-income", "referenceText": "Kino", "transactionNature": "NORMAL",
+		"createdTS": 1549800000000, "confirmed": 1549800000000
+	},
+	{
+		"id": "t-0005", "userId": "u-0001", "type": "PT", "amount": -120, "currencyCode": "EUR",
+		"visibleTS": 1549195200000, "partnerName": "ATM Alexanderplatz", "accountId": "a1b2c3d4-0000-4000-8000-000000000001",
+		"category": "micro-v2-atm", "referenceText": "", "transactionNature": "NORMAL",
+		"createdTS": 1549195200000, "confirmed": 1549195200000
+	},
+	{
+		"id": "t-0004", "userId": "u-0001", "type": "CT", "amount": 2850, "currencyCode": "EUR",
+		"visibleTS": 1549022400000, "partnerName": "ACME GmbH", "partnerIban": "DE02120300000000202051",
+		"partnerBic": "BYLADEM1001", "accountId": "a1b2c3d4-0000-4000-8000-000000000001",
+		"category": "micro-v2-income", "referenceText": "Gehalt Februar 2019", "transactionNature": "NORMAL",
+		"createdTS": 1549022400000, "confirmed": 1549022400000
+	},
+	{
+		"id": "t-0003", "userId": "u-0001", "type": "DT", "amount": -950, "currencyCode": "EUR",
+		"visibleTS": 1548936000000, "partnerName": "Hausverwaltung Schmidt", "partnerIban": "DE02100500000054540402",
+		"partnerBic": "BELADEBEXXX", "accountId": "a1b2c3d4-0000-4000-8000-000000000001",
+		"category": "micro-v2-household-utilities", "referenceText": "Miete Februar", "recurring": true,
+		"transactionNature": "NORMAL", "createdTS": 1548936000000, "confirmed": 1548936000000
+	}
+]`
+
+const categoriesJSON = `[
+	{"id": "micro-v2-atm", "name": "ATM"},
+	{"id": "micro-v2-food-groceries", "name": "Food & Groceries"},
+	{"id": "micro-v2-household-utilities", "name": "Household & Utilities"},
+	{"id": "micro-v2-income", "name": "Income"},
+	{"id": "micro-v2-media-electronics", "name": "Media & Electronics"},
+	{"id": "micro-v2-travel-holidays", "name": "Travel & Holidays"}
+]`
+
+const contactsJSON = `[
+	{
+		"userId": "u-0001", "id": "c-0001", "name": "Max Mustermann", "subtitle": "DE12 5001 0517 0648 4898 90",
+		"account": {"accountType": "sepa", "iban": "DE12500105170648489890", "bic": "INGDDEFFXXX"}
+	},
+	{
+		"userId": "u-0001", "id": "c-0002", "name": "Hausverwaltung Schmidt", "subtitle": "DE02 1005 0000 0054 5404 02",
+		"account": {"accountType": "sepa", "iban": "DE02100500000054540402", "bic": "BELADEBEXXX"}
+	}
+]`
+
+const limitsJSON = `[
+	{"limit": "ATM_DAILY_ACCOUNT", "amount": 2500, "currency": "EUR"},
+	{"limit": "POS_DAILY_ACCOUNT", "amount": 5000, "currency": "EUR"}
+]`
+
+const meJSON = `{
+	"id": "u-0001",
+	"email": "jane.doe@example.com",
+	"firstName": "Jane",
+	"lastName": "Doe",
+	"kycFirstName": "Jane",
+	"kycLastName": "Doe",
+	"title": "",
+	"gender": "FEMALE",
+	"birthDate": 631152000000,
+	"signupCompleted": true,
+	"nationality": "DEU",
+	"mobilePhoneNumber": "+4915123456789",
+	"shadowUserId": "s-0001",
+	"transferWiseTermsAccepted": false
+}`
+
+const statusesJSON = `{
+	"id": "u-0001",
+	"created": 1483228800000,
+	"updated": 1553083200000,
+	"singleStepSignup": 1483228800000,
+	"emailValidationInitiated": 1483228800000,
+	"emailValidationCompleted": 1483232400000,
+	"productSelectionCompleted": 1483232400000,
+	"phonePairingInitiated": 1483232400000,
+	"phonePairingCompleted": 1483236000000,
+	"kycInitiated": 1483236000000,
+	"kycCompleted": 1483322400000,
+	"kycWebIDInitiated": 1483236000000,
+	"kycWebIDCompleted": 1483322400000,
+	"cardActivationCompleted": 1483927200000,
+	"pinDefinitionCompleted": 1483927200000,
+	"bankAccountCreationInitiated": 1483322400000,
+	"bankAccountCreationSucceded": 1483322400000,
+	"coreDataUpdated": 1553083200000,
+	"firstIncomingTransaction": 1483920000000,
+	"flexAccount": false
+}`
+
+const statementsJSON = `[
+	{"id": "statement-2019-02", "url": "/api/statements/statement-2019-02", "visibleTS": 1551398400000, "month": 2, "year": 2019},
+	{"id": "statement-2019-01", "url": "/api/statements/statement-2019-01", "visibleTS": 1548979200000, "month": 1, "year": 2019},
+	{"id": "statement-2018-12", "url": "/api/statements/statement-2018-12", "visibleTS": 1546300800000, "month": 12, "year": 2018}
+]`
+
+const cardsJSON = `[
+	{
+		"id": "card-0001", "maskedPan": "535229******1234", "expirationDate": 1640908800000,
+		"cardType": "MASTERCARD", "status": "M_ACTIVE", "cardProductType": "STANDARD",
+		"pinDefined": 1483927200000, "cardActivated": 1483927200000, "usernameOnCard": "JANE DOE",
+		"mptsCard": false
+	},
+	{
+		"id": "card-0002", "maskedPan": "492942******5678", "expirationDate": 1640908800000,
+		"cardType": "MAESTRO", "status": "M_ACTIVE", "cardProductType": "MAESTRO",
+		"pinDefined": 1483927200000, "cardActivated": 1483927200000, "usernameOnCard": "JANE DOE",
+		"mptsCard": false
+	}
+]`
+
+const savingsJSON = `{
+	"totalBalance": 1020.35,
+	"canOpenMore": true,
+	"accounts": [
+		{
+			"id": "s-0001", "name": "Rainy Day", "monthlyAmount": 50, "nextDate": "2019-04-01",
+			"history": [], "forecasts": [], "optionId": "balanced", "startingDate": "2018-01-01",
+			"balance": 1020.35, "totalDeposit": 1000, "performance": 0.0203, "profit": 20.35,
+			"status": "ACTIVE"
+		}
+	],
+	"pendingAccounts": []
+}`
+
+const spacesJSON = `{
+	"totalBalance": 1773.57,
+	"spaces": [
+		{
+			"id": "sp-0001", "accountId": "a1b2c3d4-0000-4000-8000-000000000001", "name": "Main Account",
+			"balance": {"availableBalance": 1523.57, "currency": "EUR", "overdraftAmount": 0},
+			"isPrimary": true, "isCardAttached": true
+		},
+		{
+			"id": "sp-0002", "accountId": "a1b2c3d4-0000-4000-8000-000000000002", "name": "Holidays",
+			"balance": {"availableBalance": 250, "currency": "EUR", "overdraftAmount": 0},
+			"isPrimary": false, "isCardAttached": false
+		}
+	],
+	"userFeatures": {"availableSpaces": 8, "canUpgrade": true}
+}`
+
+// statementPDF is a minimal, valid single page PDF
+const statementPDF = `%PDF-1.4
+1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj
+2 0 obj << /Type /Pages /Kids [3 0 R] /Count 1 >> endobj
+3 0 obj << /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >> endobj
+trailer << /Root 1 0 R >>
+%%EOF
+`
+
+// statementCSV is a statement in the CSV layout of N26
+const statementCSV = `"Date","Payee","Account number","Transaction type","Payment reference","Category","Amount (EUR)","Amount (Foreign Currency)","Type Foreign Currency","Exchange Rate"
+"2019-02-01","ACME GmbH","DE02120300000000202051","Income","Gehalt Februar 2019","Income","2850.0","","",""
+"2019-02-03","ATM Alexanderplatz","","MasterCard Payment","","ATM","-120.0","-120.0","EUR","1.0"
+"2019-02-10","Max Mustermann","DE12500105170648489890","Income","Kino","Income","25.0","","",""
+"2019-02-14","Restaurant Da Mario","","MasterCard Payment","","Food & Groceries","-64.2","-64.2","EUR","1.0"
+"2019-02-28","Hausverwaltung Schmidt","DE02100500000054540402","Outgoing Transfer","Miete Maerz","Household & Utilities","-950.0","","",""
+`
@@ -0,0 +1,445 @@
+// Package n26test provides a fake N26 API with seeded fixture data for
+// tests and offline development
+package n26test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/njuettner/n26"
+)
+
+const (
+	// Email of the seeded customer
+	Email = "jane.doe@example.com"
+	// Password of the seeded customer
+	Password = "password"
+	// OTP is the one-time password accepted for two-factor logins via SMS
+	OTP = "123456"
+
+	tokenLifetime = 30 * time.Minute
+	// maxStatsTimestamp is the year 5000 in seconds since epoch
+	maxStatsTimestamp = 95617584000
+)
+
+// Handler serves the fake N26 API
+type Handler struct {
+	// Email and Password accepted by the password grant
+	Email    string
+	Password string
+	// MFA makes password logins answer with mfa_required
+	MFA bool
+	// MFAPendingPolls is the number of polls answered with
+	// authorization_pending before an app approval is granted
+	MFAPendingPolls int
+
+	Account      n26.N26Account
+	Transactions n26.N26Transactions
+	Categories   n26.N26Categories
+	Contacts     n26.N26Contacts
+	Limits       n26.N26AccountLimit
+	Info         n26.N26AccountInfo
+	Status       n26.N26AccountStatus
+	Statements   n26.N26BankStatements
+	Cards        n26.N26Cards
+	Savings      n26.N26Savings
+	Spaces       n26.N26Spaces
+
+	mu            sync.Mutex
+	mux           *http.ServeMux
+	accessTokens  map[string]time.Time
+	refreshTokens map[string]bool
+	mfaTokens     map[string]bool
+	mfaPolls      map[string]int
+}
+
+// NewHandler returns a fake N26 API seeded with fixture data
+func NewHandler() *Handler {
+	h := &Handler{
+		Email:         Email,
+		Password:      Password,
+		accessTokens:  map[string]time.Time{},
+		refreshTokens: map[string]bool{},
+		mfaTokens:     map[string]bool{},
+		mfaPolls:      map[string]int{},
+	}
+	mustDecode(accountJSON, &h.Account)
+	mustDecode(transactionsJSON, &h.Transactions)
+	mustDecode(categoriesJSON, &h.Categories)
+	mustDecode(contactsJSON, &h.Contacts)
+	mustDecode(limitsJSON, &h.Limits)
+	mustDecode(meJSON, &h.Info)
+	mustDecode(statusesJSON, &h.Status)
+	mustDecode(statementsJSON, &h.Statements)
+	mustDecode(cardsJSON, &h.Cards)
+	mustDecode(savingsJSON, &h.Savings)
+	mustDecode(spacesJSON, &h.Spaces)
+
+	h.mux = http.NewServeMux()
+	h.mux.HandleFunc("/oauth/token", h.token)
+	h.mux.HandleFunc("/api/mfa/challenge", h.challenge)
+	h.mux.HandleFunc("/api/accounts", h.authorized(h.get(&h.Account)))
+	h.mux.HandleFunc("/api/accounts/stats/", h.authorized(h.stats))
+	h.mux.HandleFunc("/api/smrt/transactions", h.authorized(h.transactions))
+	h.mux.HandleFunc("/api/smrt/categories", h.authorized(h.get(&h.Categories)))
+	h.mux.HandleFunc("/api/smrt/contacts", h.authorized(h.get(&h.Contacts)))
+	h.mux.HandleFunc("/api/settings/account/limits", h.authorized(h.get(&h.Limits)))
+	h.mux.HandleFunc("/api/me", h.authorized(h.get(&h.Info)))
+	h.mux.HandleFunc("/api/me/statuses", h.authorized(h.get(&h.Status)))
+	h.mux.HandleFunc("/api/statements", h.authorized(h.get(&h.Statements)))
+	h.mux.HandleFunc("/api/statements/", h.authorized(h.statement))
+	h.mux.HandleFunc("/api/v2/cards", h.authorized(h.get(&h.Cards)))
+	h.mux.HandleFunc("/api/cards/", h.authorized(h.card))
+	h.mux.HandleFunc("/api/hub/savings/accounts", h.authorized(h.get(&h.Savings)))
+	h.mux.HandleFunc("/api/spaces", h.authorized(h.get(&h.Spaces)))
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// Server is a fake N26 API listening on a local address
+type Server struct {
+	*httptest.Server
+	API *Handler
+}
+
+// NewServer starts a fake N26 API, the caller should call Close when finished
+func NewServer() *Server {
+	api := NewHandler()
+	return &Server{Server: httptest.NewServer(api), API: api}
+}
+
+// Client returns an n26 client logged in as the seeded customer
+func (s *Server) Client(opts ...n26.Option) *n26.Client {
+	opts = append([]n26.Option{n26.WithBaseURL(s.URL)}, opts...)
+	return n26.NewClient(s.API.Email, s.API.Password, opts...)
+}
+
+func (h *Handler) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Use POST")
+		return
+	}
+	if user, pass, ok := r.BasicAuth(); !ok || user != "android" || pass != "secret" {
+		writeError(w, http.StatusUnauthorized, "invalid_client", "Bad client credentials")
+		return
+	}
+	err := r.ParseForm()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch r.Form.Get("grant_type") {
+	case "password":
+		if r.Form.Get("username") != h.Email || r.Form.Get("password") != h.Password {
+			writeError(w, http.StatusBadRequest, "invalid_grant", "Bad credentials")
+			return
+		}
+		if h.MFA {
+			mfaToken := newToken()
+			h.mfaTokens[mfaToken] = true
+			writeJSON(w, http.StatusForbidden, map[string]string{
+				"error":             "mfa_required",
+				"error_description": "MFA token is required",
+				"mfaToken":          mfaToken,
+			})
+			return
+		}
+	case "mfa_oob", "mfa_otp":
+		if !h.mfaTokens[r.Form.Get("mfaToken")] {
+			writeError(w, http.StatusBadRequest, "invalid_grant", "Unknown MFA token")
+			return
+		}
+		if r.Form.Get("grant_type") == "mfa_otp" && r.Form.Get("otp") != OTP {
+			writeError(w, http.StatusBadRequest, "invalid_otp", "Wrong one-time password")
+			return
+		}
+		if r.Form.Get("grant_type") == "mfa_oob" && h.mfaPolls[r.Form.Get("mfaToken")] < h.MFAPendingPolls {
+			h.mfaPolls[r.Form.Get("mfaToken")]++
+			writeError(w, http.StatusBadRequest, "authorization_pending", "Login not approved yet")
+			return
+		}
+		delete(h.mfaTokens, r.Form.Get("mfaToken"))
+		delete(h.mfaPolls, r.Form.Get("mfaToken"))
+	case "refresh_token":
+		if !h.refreshTokens[r.Form.Get("refresh_token")] {
+			writeError(w, http.StatusUnauthorized, "invalid_token", "Invalid refresh token")
+			return
+		}
+		delete(h.refreshTokens, r.Form.Get("refresh_token"))
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported_grant_type", "Unsupported grant type")
+		return
+	}
+	accessToken, refreshToken := newToken(), newToken()
+	h.accessTokens[accessToken] = time.Now().Add(tokenLifetime)
+	h.refreshTokens[refreshToken] = true
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  accessToken,
+		"token_type":    "bearer",
+		"refresh_token": refreshToken,
+		"expires_in":    int(tokenLifetime.Seconds()),
+		"scope":         "trust",
+	})
+}
+
+func (h *Handler) challenge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Use POST")
+		return
+	}
+	body := struct {
+		ChallengeType string `json:"challengeType"`
+		MFAToken      string `json:"mfaToken"`
+	}{}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.mfaTokens[body.MFAToken] {
+		writeError(w, http.StatusBadRequest, "invalid_grant", "Unknown MFA token")
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"challengeType": body.ChallengeType})
+}
+
+// authorized rejects requests without a valid access token
+func (h *Handler) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		h.mu.Lock()
+		expiry, ok := h.accessTokens[token]
+		h.mu.Unlock()
+		if !ok || time.Now().After(expiry) {
+			writeError(w, http.StatusUnauthorized, "invalid_token", "Access token expired or invalid")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// get serves a fixture as JSON
+func (h *Handler) get(v interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Use GET")
+			return
+		}
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+func (h *Handler) transactions(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	transactions := h.Transactions
+	query := r.URL.Query()
+	if query.Get("from") != "" || query.Get("to") != "" {
+		from, errFrom := strconv.ParseInt(query.Get("from"), 10, 64)
+		to, errTo := strconv.ParseInt(query.Get("to"), 10, 64)
+		if errFrom != nil || errTo != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "from and to must be given in milliseconds")
+			return
+		}
+		filtered := n26.N26Transactions{}
+		for _, transaction := range transactions {
+			if transaction.VisibleTS >= from && transaction.VisibleTS <= to {
+				filtered = append(filtered, transaction)
+			}
+		}
+		transactions = filtered
+	}
+	if lastID := query.Get("lastId"); lastID != "" {
+		found := false
+		for i, transaction := range transactions {
+			if transaction.ID == lastID {
+				transactions = transactions[i+1:]
+				found = true
+				break
+			}
+		}
+		if !found {
+			writeError(w, http.StatusBadRequest, "invalid_request", "Unknown lastId")
+			return
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid_request", "Invalid limit")
+			return
+		}
+		if n < len(transactions) {
+			transactions = transactions[:n]
+		}
+	}
+	writeJSON(w, http.StatusOK, transactions)
+}
+
+// stats calculates the balances back from the account balance or sums up
+// the booked transactions per category
+func (h *Handler) stats(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	query := r.URL.Query()
+	from, errFrom := strconv.ParseInt(query.Get("from"), 10, 64)
+	to, errTo := strconv.ParseInt(query.Get("to"), 10, 64)
+	slices, errSlices := strconv.Atoi(query.Get("numSlices"))
+	// timestamps in milliseconds would be after the year 5000
+	if errFrom != nil || errTo != nil || to < from || to > maxStatsTimestamp {
+		writeError(w, http.StatusBadRequest, "invalid_request", "from and to must be given in seconds")
+		return
+	}
+	if errSlices != nil || slices < 1 {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid numSlices")
+		return
+	}
+	stats := n26.N26Stats{From: from, To: to, Type: query.Get("type")}
+	switch stats.Type {
+	case n26.StatsAccount:
+		// balance after all booked transactions up to a point in time
+		balanceAt := func(ts int64) float64 {
+			balance := h.Account.BankBalance
+			for _, transaction := range h.Transactions {
+				if !transaction.Pending && transaction.VisibleTS > ts*1000 {
+					balance -= transaction.Amount
+				}
+			}
+			return math.Round(balance*100) / 100
+		}
+		step := (to - from) / int64(slices)
+		for i := 0; i < slices; i++ {
+			item := n26.N26StatsItem{From: from + int64(i)*step, To: from + int64(i+1)*step}
+			if i == slices-1 {
+				item.To = to
+			}
+			item.ID = strconv.Itoa(i)
+			item.Amount = balanceAt(item.To)
+			stats.Items = append(stats.Items, item)
+		}
+	case n26.StatsCategory:
+		amounts := map[string]float64{}
+		var categories []string
+		for _, transaction := range h.Transactions {
+			if transaction.Pending || transaction.VisibleTS < from*1000 || transaction.VisibleTS > to*1000 {
+				continue
+			}
+			if _, ok := amounts[transaction.Category]; !ok {
+				categories = append(categories, transaction.Category)
+			}
+			amounts[transaction.Category] += transaction.Amount
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			stats.Items = append(stats.Items, n26.N26StatsItem{
+				ID:     category,
+				From:   from,
+				To:     to,
+				Amount: math.Round(amounts[category]*100) / 100,
+			})
+		}
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_request", "type must be acct or cat")
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (h *Handler) statement(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/statements/")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, statement := range h.Statements {
+		if statement.ID == id {
+			if r.Header.Get("Accept") == "text/csv" {
+				w.Header().Set("Content-Type", "text/csv")
+				io.WriteString(w, statementCSV)
+				return
+			}
+			w.Header().Set("Content-Type", "application/pdf")
+			io.WriteString(w, statementPDF)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "not_found", "Statement not found")
+}
+
+func (h *Handler) card(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/cards/"), "/")
+	if len(parts) != 2 || (parts[1] != "block" && parts[1] != "unblock") {
+		writeError(w, http.StatusNotFound, "not_found", "Not found")
+		return
+	}
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Use POST")
+		return
+	}
+	status := "M_ACTIVE"
+	if parts[1] == "block" {
+		status = "M_DISABLED"
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, card := range h.Cards {
+		if card.ID != parts[0] {
+			continue
+		}
+		h.Cards[i].Status = status
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":             card.ID,
+			"maskedPan":      card.MaskedPan,
+			"expirationDate": card.ExpirationDate,
+			"cardType":       card.CardType,
+			"pinDefined":     card.PinDefined,
+			"cardActivated":  card.CardActivated,
+		})
+		return
+	}
+	writeError(w, http.StatusNotFound, "not_found", "Card not found")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, description string) {
+	writeJSON(w, status, n26.N26Error{Error: code, ErrorDescription: description})
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func mustDecode(data string, v interface{}) {
+	err := json.Unmarshal([]byte(data), v)
+	if err != nil {
+		panic(fmt.Sprintf("n26test: invalid fixture: %s", err))
+	}
+}
@@ -0,0 +1,74 @@
+package n26
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithBaseURL points the client to another API, e.g. a local stand-in
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for logins and API calls
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport sets the round tripper used for logins and API calls,
+// e.g. a proxy or a recording transport
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// WithTimeout limits the duration of every single HTTP request
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// baseHTTPClient returns the HTTP client without OAuth, as configured by the options
+func (c *Client) baseHTTPClient() *http.Client {
+	httpClient := &http.Client{}
+	if c.httpClient != nil {
+		*httpClient = *c.httpClient
+	}
+	if c.transport != nil {
+		httpClient.Transport = c.transport
+	}
+	if c.timeout > 0 {
+		httpClient.Timeout = c.timeout
+	}
+	return httpClient
+}
+
+func (c *Client) apiURL(path string) string {
+	if c.baseURL == "" {
+		return N26APIUrl + path
+	}
+	return c.baseURL + path
+}
+
+func (c *Client) setUserAgent(req *http.Request) {
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+}
@@ -0,0 +1,168 @@
+package n26
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenStore persists OAuth tokens between runs
+type TokenStore interface {
+	Token() (*oauth2.Token, error)
+	SetToken(token *oauth2.Token) error
+}
+
+// FileTokenStore keeps the OAuth token as JSON in a file
+type FileTokenStore struct {
+	Path string
+}
+
+// NewFileTokenStore returns a TokenStore backed by the given file
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{Path: path}
+}
+
+// Token reads the stored token, a missing file returns no token and no error
+func (s *FileTokenStore) Token() (*oauth2.Token, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	token := &oauth2.Token{}
+	err = json.Unmarshal(data, token)
+	if err != nil {
+		return nil, fmt.Errorf("could not read token from %s: %w", s.Path, err)
+	}
+	return token, nil
+}
+
+// SetToken writes the token to the file, readable only by the current user
+func (s *FileTokenStore) SetToken(token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(s.Path), 0700)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.Path, data, 0600)
+}
+
+// tokenSource hands out the cached token, refreshes it once it expired and
+// only logs in with the password again if the refresh token was rejected
+type tokenSource struct {
+	client *Client
+	mu     sync.Mutex
+	token  *oauth2.Token
+}
+
+func (s *tokenSource) Token(ctx context.Context) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == nil && s.client.TokenStore != nil {
+		// an unreadable token is replaced by a new login
+		token, err := s.client.TokenStore.Token()
+		if err == nil {
+			s.token = token
+		}
+	}
+	if s.token.Valid() {
+		return s.token, nil
+	}
+	var token *oauth2.Token
+	var err error
+	if s.token != nil && s.token.RefreshToken != "" {
+		v := url.Values{}
+		v.Set("grant_type", "refresh_token")
+		v.Set("refresh_token", s.token.RefreshToken)
+		token, err = s.client.requestToken(ctx, v)
+		// only a rejected refresh token needs the password, other errors
+		// would trigger a needless two-factor login
+		if err != nil && !IsUnauthorized(err) {
+			return nil, err
+		}
+	}
+	if token == nil || err != nil {
+		token, err = s.client.login(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if token.RefreshToken == "" && s.token != nil {
+		token.RefreshToken = s.token.RefreshToken
+	}
+	s.token = token
+	if s.client.TokenStore != nil {
+		err = s.client.TokenStore.SetToken(token)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+// invalidate drops the token so the next call refreshes it, the token is
+// replaced by an expired copy as other requests may still read it
+func (s *tokenSource) invalidate(token *oauth2.Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == token {
+		expired := *token
+		expired.AccessToken = ""
+		expired.Expiry = time.Unix(1, 0)
+		s.token = &expired
+	}
+}
+
+// authTransport authorizes every request with the token of its source,
+// logins and refreshes are bound to the context of the request
+type authTransport struct {
+	source *tokenSource
+	base   http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	authReq := new(http.Request)
+	*authReq = *req
+	authReq.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		authReq.Header[k] = v
+	}
+	token.SetAuthHeader(authReq)
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(authReq)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !isIdempotent(req) {
+		return resp, err
+	}
+	// the cached token was revoked, log in again and retry once
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	t.source.invalidate(token)
+	token, err = t.source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	token.SetAuthHeader(authReq)
+	return base.RoundTrip(authReq)
+}